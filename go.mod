@@ -5,4 +5,6 @@ go 1.16
 require (
 	github.com/google/uuid v1.1.1
 	github.com/sirupsen/logrus v1.6.0
+	golang.org/x/mod v0.4.2
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -4,76 +4,255 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
 	"semtag/pkg/changelog"
+	"semtag/pkg/component"
+	"semtag/pkg/conventionalCommit"
+	"semtag/pkg/goModule"
 	"semtag/pkg/output"
+	"semtag/pkg/remote"
+	"semtag/pkg/version"
 	"semtag/pkg/versionControl"
 )
 
 const (
 	binaryName = "semtag"
 
+	flagConfig = "config"
+	flagOutput = "output"
+
+	OutputText = "text"
+	OutputJson = "json"
+
 	flagPrefix    = "prefix"
 	flagSuffix    = "suffix"
 	flagIncrement = "increment"
 	flagVersion   = "version"
 
-	flagPath = "path"
+	flagVersionSource = "version-source"
+	flagVersionEnvVar = "version-env-var"
+	flagVersionFile   = "version-file"
+
+	flagPath  = "path"
+	flagFetch = "fetch"
 
 	flagShouldTagGit   = "git-tag"
+	flagVerify         = "verify"
 	flagShouldPush     = "push"
+	flagDryRun         = "dry-run"
 	flagExecuteCommand = "command"
 
 	flagFileName           = "file"
 	flagFileVersionPattern = "file-version-pattern"
-
-	flagChangelog      = "changelog"
-	flagChangelogRegex = "changelog-regex"
+	flagFileVersionPath    = "file-version-path"
+	flagBumpFile           = "bump-file"
+
+	flagChangelog            = "changelog"
+	flagChangelogRegex       = "changelog-regex"
+	flagChangelogSection     = "changelog-section"
+	flagChangelogIncremental = "changelog-incremental"
+	flagChangelogTemplate    = "changelog-template"
+	flagChangelogFormat      = "changelog-format"
+	flagChangelogReference   = "changelog-reference"
+	flagChangelogFooterRefs  = "changelog-footer-references"
+	flagChangelogEmails      = "changelog-emails"
+	flagChangelogFile        = "changelog-file"
+	flagGitHostType          = "git-host-type"
+	flagReleaseNotes         = "release-notes"
+	flagReleaseNotesFile     = "release-notes-file"
+	flagGitHostUrl           = "git-host-url"
+
+	flagPackageName         = "package-name"
+	flagPackageMaintainer   = "package-maintainer"
+	flagPackageDistribution = "package-distribution"
+	flagPackageUrgency      = "package-urgency"
+	flagPackageRelease      = "package-release"
+
+	flagComponent                    = "component"
+	flagComponentDependency          = "component-dependency"
+	flagComponentDependencyIncrement = "component-dependency-increment"
+
+	flagGoModules      = "go-modules"
+	flagGoMajorMigrate = "go-major-migrate"
+	flagGoVersionFile  = "go-version-file"
+	flagGoLdflags      = "go-ldflags"
+
+	flagLintTypes       = "lint-types"
+	flagLintFrom        = "lint-from"
+	flagLintMessageFile = "lint-message-file"
 )
 
 var (
-	errMissingArgs = errors.New("required arguments not found")
+	errMissingArgs     = errors.New("required arguments not found")
+	errConflictingArgs = errors.New("arguments can't be used together")
+	errUnknownCommand  = errors.New("unknown command")
+	errUnexpectedArgs  = errors.New("unexpected arguments")
+	errInvalidEnvVar   = errors.New("invalid environment variable")
+	errUnknownOutput   = errors.New("unknown output format")
 )
 
 type CliArgs struct {
+	// Command is the name of the command (e.g. next); it is empty if only flags are provided
+	Command string
+	// ConfigFile is the repository configuration file; the default file is used if it is empty
+	ConfigFile string
+	// Output is the format of the result printed to stdout: text or json
+	Output string
+	// Sources contains the source of the flags that don't have their default value: the command line, an environment variable or the configuration file
+	Sources map[string]string
+
 	Prefix               string
 	Suffix               string
 	CustomVersion        string
 	VersionScopeAsString string
 
+	VersionSources string
+	VersionEnvVar  string
+	VersionFile    string
+
 	RelevantPaths versionControl.RelevantPaths
+	Fetch         bool
 
 	Push           bool
+	DryRun         bool
 	ShouldTagGit   bool
+	Verify         bool
 	ExecuteCommand string
 
-	Changelog      bool
-	ChangelogRegex string
+	Changelog            bool
+	ChangelogRegex       string
+	ChangelogSections    changelog.Sections
+	ChangelogIncremental bool
+	ChangelogTemplate    string
+	ChangelogFormat      string
+	ChangelogReferences  changelog.ReferencePatterns
+	ChangelogFooterRefs  bool
+	ChangelogEmails      bool
+	ChangelogFile        string
+	GitHostType          string
+	ReleaseNotes         bool
+	ReleaseNotesFile     string
+	GitHostUrl           string
+
+	PackageName         string
+	PackageMaintainer   string
+	PackageDistribution string
+	PackageUrgency      string
+	PackageRelease      string
 
 	FileName           string
 	FileVersionPattern string
+	FileVersionPath    string
+	BumpFiles          version.Files
+
+	Components                       component.List
+	ComponentDependencies            component.Dependencies
+	ComponentDependencyScopeAsString string
+
+	GoModules      bool
+	GoMajorMigrate bool
+	GoVersionFile  string
+	GoLdflags      string
+
+	LintTypes       string
+	LintFrom        string
+	LintMessageFile string
 }
 
+/*
+ParseFlags parses the command line arguments:
+  - if the first argument is a command (e.g. next), only the flags of the command are accepted and the implied flags of the command are set
+  - otherwise all the flags are accepted, for backward compatibility
+  - the flags that are not provided are bound to the environment variables and to the configuration file (see bind)
+*/
 func (args *CliArgs) ParseFlags() {
-	args.loadAllFlags()
-	args.parseAndInit()
+	flag.CommandLine.Usage = usage
+	args.parse(flag.CommandLine, os.Args[1:])
+}
+
+// parse parses the arguments of a command, or all the flags with the provided flag set if the first argument isn't a command
+func (args *CliArgs) parse(fs *flag.FlagSet, arguments []string) {
+	if len(arguments) > 0 {
+		if cmd, ok := findCommand(arguments[0]); ok {
+			args.parseCommand(cmd, arguments[1:])
+			return
+		}
+	}
+	// the flag-only invocation always fetches the tags of the remote
+	args.Fetch = true
+	args.loadAllFlags(fs)
+	if err := fs.Parse(arguments); err != nil {
+		output.Logger().Fatal(err)
+	}
+	args.bind(fs)
+	if fs.NArg() > 0 {
+		output.Logger().WithFields(logrus.Fields{
+			"command":  fs.Arg(0),
+			"commands": commandNames(),
+		}).Fatalln(errUnknownCommand)
+	}
+	args.init()
 	args.guardAgainstInvalidArgs()
 }
 
-func (args *CliArgs) parseAndInit() {
-	flag.Parse()
+// parseCommand parses the flags of a command
+func (args *CliArgs) parseCommand(cmd command, arguments []string) {
+	args.Command = cmd.name
+	// the flags that aren't accepted by the command keep their default values, as in the flag-only invocation
+	args.loadAllFlags(flag.NewFlagSet(cmd.name, flag.ContinueOnError))
+	fs := cmd.flagSet(args, flag.ExitOnError)
+	if err := fs.Parse(arguments); err != nil {
+		output.Logger().WithField("command", cmd.name).Fatal(err)
+	}
+	args.bind(fs)
+	if fs.NArg() > 0 {
+		output.Logger().WithFields(logrus.Fields{
+			"command":   cmd.name,
+			"arguments": fs.Args(),
+		}).Fatalln(errUnexpectedArgs)
+	}
+	if cmd.implied != nil {
+		cmd.implied(args)
+	}
+	args.init()
+	args.guardAgainstInvalidArgs()
+}
 
+func (args *CliArgs) init() {
 	if len(args.RelevantPaths) == 0 {
 		args.RelevantPaths = versionControl.RelevantPaths{versionControl.DefaultRelevantPath}
 	}
+	// fetching prunes the local tags that are not on the remote: a dry run must leave the repository untouched
+	if args.DryRun {
+		args.Fetch = false
+	}
 
 	output.Logger().WithField("args", fmt.Sprintf("%#v", args)).Info("arguments parsed")
 }
 
-func (args *CliArgs) loadAllFlags() {
-	flag.Var(
+func (args *CliArgs) loadAllFlags(fs *flag.FlagSet) {
+	args.loadConfigFlags(fs)
+	args.loadOutputFlags(fs)
+	args.loadGenericVersionFlags(fs)
+	args.loadIncrementFlags(fs)
+	args.loadTagFlags(fs)
+	args.loadPushFlags(fs)
+	args.loadBaseActionFlags(fs)
+	args.loadChangelogFlags(fs)
+	args.loadPackageFlags(fs)
+	args.loadFileActionFlags(fs)
+	args.loadBumpFileFlags(fs)
+	args.loadComponentFlags(fs)
+	args.loadGoBuildFlags(fs)
+}
+
+func (args *CliArgs) loadTagFlags(fs *flag.FlagSet) {
+	fs.Var(
 		&args.RelevantPaths,
 		flagPath,
 		fmt.Sprintf(`if set, create a git tag only if changes are detected in the provided path(s)
@@ -81,20 +260,144 @@ func (args *CliArgs) loadAllFlags() {
 	$ ./%s -%[2]s="src" -%[2]s="lib/" -%[2]s="Dockerfile"
 `,
 			binaryName, flagPath))
+}
+
+func (args *CliArgs) loadOutputFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&args.Output,
+		flagOutput,
+		OutputText,
+		fmt.Sprintf(`the format of the result printed to stdout: [ %[1]s | %[2]s ]. With %[2]s, a single JSON document describes the release plan (the previous and the next version, the scope, the version variants, the tag, whether a release is needed, the files changed, the commits analyzed and the actions performed) and the other results (e.g. the dry-run diffs) are printed to stderr; the schema is described in docs/release-plan.md
+	e.g.:
+	$ ./%[3]s %[4]s -%[5]s=%[2]s | jq -r .nextVersion
+	0.3.0
+`,
+			OutputText, OutputJson, binaryName, CommandNext, flagOutput))
+}
+
+// loadFetchFlags registers -fetch; its default value is the fetch default of the command
+func (args *CliArgs) loadFetchFlags(fs *flag.FlagSet) {
+	fs.BoolVar(
+		&args.Fetch,
+		flagFetch,
+		args.Fetch,
+		"if set, fetch the tags of the remote before reading the latest version; by default the local tags are read and the repository is left untouched")
+}
+
+func (args *CliArgs) loadLintFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&args.LintTypes,
+		flagLintTypes,
+		strings.Join(conventionalCommit.DefaultTypes, ","),
+		"the comma separated list of the allowed Conventional Commit types")
+
+	fs.StringVar(
+		&args.LintFrom,
+		flagLintFrom,
+		"",
+		fmt.Sprintf(`if set, lint the commits reachable from HEAD but not from the provided ref (e.g. origin/main for the commits of a merge request); by default the commits since the latest version tag are linted
+	e.g.:
+	$ ./%s %s -%s=origin/main
+`,
+			binaryName, CommandLint, flagLintFrom))
+
+	fs.StringVar(
+		&args.LintMessageFile,
+		flagLintMessageFile,
+		"",
+		fmt.Sprintf(`if set, lint only the commit message of the file instead of the commits of the repository; the comment lines (#) are ignored, so that it can be used in a commit-msg hook
+	e.g.:
+	$ echo './%s %s -%s="$1"' > .git/hooks/commit-msg
+`,
+			binaryName, CommandLint, flagLintMessageFile))
+}
+
+func (args *CliArgs) loadGoBuildFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&args.GoVersionFile,
+		flagGoVersionFile,
+		"",
+		fmt.Sprintf(`if set, generate or update a Go source file that declares the build information as the string constants %[1]s, %[2]s and %[3]s (the version, and the hash and the date of the HEAD commit); only the values are replaced in an existing file and the output is gofmt-clean. The file is committed together with the version files
+	e.g.:
+	$ ./%[4]s -%[5]s=auto -%[6]s=internal/build/version.go -%[7]s
+	$ cat internal/build/version.go
+		// Code generated by semtag. DO NOT EDIT.
+
+		package build
+
+		// Build information of the release
+		const (
+			%[1]s = "1.3.0"
+			%[2]s  = "0f3a2c1..."
+			%[3]s    = "2021-06-01T10:00:00Z"
+		)
+`,
+			goModule.NameVersion, goModule.NameCommit, goModule.NameDate, binaryName, flagIncrement, flagGoVersionFile, flagShouldPush))
+
+	fs.StringVar(
+		&args.GoLdflags,
+		flagGoLdflags,
+		"",
+		fmt.Sprintf(`if set, print the linker flags that set the build information in the string variables %[1]s, %[2]s and %[3]s of the provided Go package, instead of the version number; it can't be used together with -%[7]s or -%[8]s
+	e.g.:
+	$ go build -ldflags "$(./%[4]s -%[5]s=auto -%[6]s=example.com/app/internal/build)" .
+`,
+			goModule.NameVersion, goModule.NameCommit, goModule.NameDate, binaryName, flagIncrement, flagGoLdflags, flagReleaseNotes, flagReleaseNotesFile))
+}
+
+func (args *CliArgs) loadComponentFlags(fs *flag.FlagSet) {
+	fs.Var(
+		&args.Components,
+		flagComponent,
+		fmt.Sprintf(`if set, version the component(s) of a monorepo independently; a component is released only if changes are detected in its path(s) and it is tagged as <name>/<prefix><version>. With -%[4]s, each released component gets its own changelog in its first path (e.g. services/api/CHANGELOG.md), with only the commits that changed its path(s) between its own tags
+	e.g.:
+	$ ./%[1]s -%[2]s=auto -%[3]s="shared-lib=lib/shared" -%[3]s="api=services/api,proto/api"
+`,
+			binaryName, flagIncrement, flagComponent, flagChangelog))
 
-	args.loadGenericVersionFlags()
-	args.loadBaseActionFlags()
-	args.loadChangelogFlags()
-	args.loadFileActionFlags()
+	fs.Var(
+		&args.ComponentDependencies,
+		flagComponentDependency,
+		fmt.Sprintf(`if set, release a component when at least one of the components it depends on is released
+	e.g.:
+	$ ./%s -%s=auto -%[3]s="shared-lib=lib/shared" -%[3]s="api=services/api" -%[4]s="api=shared-lib"
+`,
+			binaryName, flagIncrement, flagComponent, flagComponentDependency))
+
+	fs.StringVar(
+		&args.ComponentDependencyScopeAsString,
+		flagComponentDependencyIncrement,
+		"patch",
+		"the version scope to increment for a component when one of its dependencies is released: [ major | minor | patch ]")
+
+	fs.BoolVar(
+		&args.GoModules,
+		flagGoModules,
+		false,
+		fmt.Sprintf(`if set, discover the Go modules (go.mod files) of the repository and version each module from its own tags and the changes in its own directory; the tags follow the Go conventions: v<version> for the root module and <dir>/v<version> for nested modules. A major version greater than 1 is refused unless the module path ends with the matching /vN suffix
+	e.g.:
+	$ ./%s -%s=auto -%s -%s
+	v1.4.0
+	tools/cli/v2.0.1
+`,
+			binaryName, flagIncrement, flagGoModules, flagShouldTagGit))
+
+	fs.BoolVar(
+		&args.GoMajorMigrate,
+		flagGoMajorMigrate,
+		false,
+		fmt.Sprintf(`if set together with -%s, migrate the module path of a Go module released as a new major version (e.g. example.com/x -> example.com/x/v2): the module directive in go.mod and the imports of the module's packages in the .go files are rewritten, as well as the requirements of the module in the go.mod files of the other modules of the repository. With -%s, the files are committed before the module is tagged; otherwise they are left unstaged`,
+			flagGoModules, flagShouldPush))
 }
 
-func (args *CliArgs) loadFileActionFlags() {
-	flag.StringVar(
+func (args *CliArgs) loadFileActionFlags(fs *flag.FlagSet) {
+	fs.StringVar(
 		&args.FileName,
 		flagFileName,
 		"",
-		`a file that contains the version number (e.g. setup.py)`)
-	flag.StringVar(&args.FileVersionPattern, "file-version-pattern", "", `the pattern expected for the file version
+		fmt.Sprintf(`a file that contains the version number (e.g. setup.py). The version is located with -%s or -%s; for well-known manifests a built-in preset is used if neither is set: %s`,
+			flagFileVersionPattern, flagFileVersionPath, presetNames()))
+	fs.StringVar(&args.FileVersionPattern, "file-version-pattern", "", `the pattern expected for the file version
 	e.g.:
 	$ cat setup.py
 		setup(
@@ -111,42 +414,308 @@ func (args *CliArgs) loadFileActionFlags() {
 `,
 		binaryName, flagIncrement, flagFileName, flagFileVersionPattern))
 
+	fs.StringVar(
+		&args.FileVersionPath,
+		flagFileVersionPath,
+		"",
+		fmt.Sprintf(`the path of the version value in a structured file (JSON, YAML, TOML or XML); only the value is replaced, so the formatting and comments of the file are preserved
+	e.g.:
+	$ ./%[1]s -%[2]s=auto -%[3]s=package.json -%[4]s=.version
+	$ ./%[1]s -%[2]s=auto -%[3]s=Chart.yaml -%[4]s=.appVersion
+	$ ./%[1]s -%[2]s=auto -%[3]s=Cargo.toml -%[4]s=package.version
+	$ ./%[1]s -%[2]s=auto -%[3]s=pom.xml -%[4]s=/project/version
+`,
+			binaryName, flagIncrement, flagFileName, flagFileVersionPath))
+}
+
+func (args *CliArgs) loadBumpFileFlags(fs *flag.FlagSet) {
+	fs.Var(
+		&args.BumpFiles,
+		flagBumpFile,
+		fmt.Sprintf(`if set, update the version in several files with a single commit; the flag can be repeated and each value has one of the formats:
+		<file>                    use the built-in preset of a well-known manifest: %[5]s
+		<file>:<version path>     the path of the version value in a structured file (JSON, YAML, TOML or XML)
+		<file>=<version format>   the pattern expected for the file version (see -%[4]s)
+	all the files are validated before any of them is written; if a file can't be updated, the files already written are restored
+	e.g.:
+	$ ./%[1]s -%[2]s=auto -%[3]s=package.json -%[3]s=Chart.yaml:.appVersion -%[3]s=VERSION -%[3]s='version.go=const Version = "%%s"'
+`,
+			binaryName, flagIncrement, flagBumpFile, flagFileVersionPattern, presetNames()))
+}
+
+// VersionSourceConfig returns the settings of the version sources
+func (args *CliArgs) VersionSourceConfig() version.SourceConfig {
+	cfg := version.SourceConfig{
+		Prefix:        args.Prefix,
+		Suffix:        args.Suffix,
+		EnvVar:        args.VersionEnvVar,
+		CustomVersion: args.CustomVersion,
+	}
+	if args.VersionFile != "" {
+		f, err := version.ParseFileSpec(args.VersionFile)
+		if err != nil {
+			output.Logger().WithField("flag", flagVersionFile).Fatal(err)
+		}
+		cfg.File = f
+	} else if files := args.VersionFiles(); len(files) > 0 {
+		cfg.File = files[0]
+	}
+	return cfg
+}
+
+// VersionFiles returns all the files that contain the version number: the file provided with -file and the files provided with -bump-file
+func (args *CliArgs) VersionFiles() version.Files {
+	var files version.Files
+	if args.FileName != "" {
+		files = append(files, version.File{
+			Path:          args.FileName,
+			VersionFormat: args.FileVersionPattern,
+			VersionPath:   args.FileVersionPath,
+		})
+	}
+	return append(files, args.BumpFiles...)
 }
 
-func (args *CliArgs) loadChangelogFlags() {
-	flag.StringVar(
+// presetNames returns the sorted names of the files that have a built-in preset; a preset updates a single value
+func presetNames() string {
+	var names []string
+	for name := range version.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ") + " (the preset of Chart.yaml updates only the chart version .version; use Chart.yaml:.appVersion for the version of the application)"
+}
+
+func (args *CliArgs) loadChangelogFlags(fs *flag.FlagSet) {
+	fs.StringVar(
 		&args.ChangelogRegex,
 		flagChangelogRegex,
 		changelog.DefaultRegexFormat,
 		"if set, generate the changelog only for specific tags")
 
-	flag.BoolVar(
+	fs.Var(
+		&args.ChangelogSections,
+		flagChangelogSection,
+		fmt.Sprintf(`if set, group the commits of each release in the provided sections, in the order of the flags; a section contains the commits of the listed Conventional Commit types, %[1]q for the breaking changes (their BREAKING CHANGE text is quoted) and %[2]q for the commits of any other type. Default: %[3]s
+	e.g.:
+	$ ./%[4]s -%[5]s -%[6]s="Breaking Changes=%[1]s" -%[6]s="New Features=feat" -%[6]s="Fixes=fix,perf"
+`,
+			changelog.SectionTypeBreaking, changelog.SectionTypeOther, changelog.DefaultSections.String(), binaryName, flagChangelog, flagChangelogSection))
+
+	fs.BoolVar(
+		&args.ChangelogIncremental,
+		flagChangelogIncremental,
+		false,
+		fmt.Sprintf(`if set together with -%[1]s, insert only the section of the new release below the line %[2]s of the existing changelog, instead of regenerating the whole file; the rest of the file (e.g. manual notes) is left untouched and the release is not inserted again if it is already in the file. A new changelog with the marker is created if the file doesn't exist
+	e.g.:
+	$ ./%[3]s -%[4]s=minor -%[1]s -%[5]s
+`,
+			flagChangelog, changelog.InsertMarker, binaryName, flagIncrement, flagChangelogIncremental))
+
+	fs.StringVar(
+		&args.ChangelogTemplate,
+		flagChangelogTemplate,
+		"",
+		fmt.Sprintf(`if set, render the changelog with the provided Go text/template file instead of the template of the built-in format; the data model and the functions of the templates are described in docs/changelog-template.md
+	e.g.:
+	$ ./%s -%s -%s=docs/confluence.tmpl
+`,
+			binaryName, flagChangelog, flagChangelogTemplate))
+
+	fs.StringVar(
+		&args.ChangelogFormat,
+		flagChangelogFormat,
+		changelog.DefaultFormat,
+		fmt.Sprintf(`if set together with -%[1]s, render the changelog in one of the built-in formats: %[2]s. The format sets the default file name (e.g. CHANGELOG.json) and the default sections (e.g. Added, Changed, Fixed and Security for %[3]s); the JSON document has a versioned schema described in docs/changelog-template.md
+	e.g.:
+	$ ./%[4]s -%[1]s -%[5]s=%[3]s
+`,
+			flagChangelog, strings.Join(changelog.FormatNames(), ", "), changelog.FormatKeepAChangelog, binaryName, flagChangelogFormat))
+
+	fs.BoolVar(
 		&args.Changelog,
 		flagChangelog,
 		false,
-		fmt.Sprintf(`if set, generate a full changelog for the repository. In order to have correct hyperlinks you will need to provide two environment variables for your web-based git repository: %[1]s for the URL of the commits and %[2]s for the URL of the tags
+		fmt.Sprintf(`if set, generate a full changelog for the repository. The hyperlinks of the commits, the tags, the comparisons and the issues are derived from the URL of the remote %[1]s for GitHub, GitLab, Bitbucket and Gitea (see -%[2]s for self-hosted instances); they can be overridden with the environment variables %[3]s, %[4]s, %[5]s and %[6]s, which accept a base URL or a URL pattern with the placeholders {hash}, {tag}, {previous} and {id}. The changelog has no hyperlinks if they can't be derived
+	e.g.:
+	$ %[3]s="https://gitlab.com/my_org/my_group/my_repository/-/commit/" %[4]s="https://gitlab.com/my_org/my_group/my_repository/-/tags/" ./%[7]s -%[8]s
+	output: a full repository changelog in a file (%[9]s) that shows the commit name(s) included in each tag
+`,
+			changelog.DefaultRemote, flagGitHostType, changelog.EnvVarGitCommitUrl, changelog.EnvVarGitTagUrl, changelog.EnvVarGitCompareUrl, changelog.EnvVarGitIssueUrl, binaryName, flagChangelog, changelog.DefaultChangelogFile))
+
+	fs.Var(
+		&args.ChangelogReferences,
+		flagChangelogReference,
+		fmt.Sprintf(`if set together with -%[1]s, link the references to tickets in the commit subjects with the format regex=url; the id of a reference is the first capturing group of the regex (or the whole match) and it replaces {id} in the url. The issues (#123) and the GitLab merge requests (!45) are linked with the URLs derived from the git remote (or %[2]s and %[3]s). The references of each release are listed in a "Referenced Issues" section
+	e.g.:
+	$ ./%[4]s -%[1]s -%[5]s='[A-Z]+-[0-9]+=https://jira.example.com/browse/{id}'
+`,
+			flagChangelog, changelog.EnvVarGitIssueUrl, changelog.EnvVarGitMergeRequestUrl, binaryName, flagChangelogReference))
+
+	fs.BoolVar(
+		&args.ChangelogFooterRefs,
+		flagChangelogFooterRefs,
+		false,
+		fmt.Sprintf(`if set together with -%[1]s, also collect the references in the footers of the commits (e.g. Closes #12, Fixes: PROJ-987, Refs: #3); the issues closed by a footer are marked as closed in the "Referenced Issues" section
+	e.g.:
+	$ ./%[2]s -%[1]s -%[3]s
+`,
+			flagChangelog, binaryName, flagChangelogFooterRefs))
+
+	fs.BoolVar(
+		&args.ChangelogEmails,
+		flagChangelogEmails,
+		false,
+		fmt.Sprintf(`if set together with -%[1]s, publish the email addresses of the commit authors and of the contributors in the changelog; by default only their names are rendered. The contributors of each release (the authors and the Co-authored-by co-authors, deduplicated with the .mailmap of the repository) are listed in a "Contributors" section, and the first-time contributors are highlighted
+	e.g.:
+	$ ./%[2]s -%[1]s -%[3]s
+`,
+			flagChangelog, binaryName, flagChangelogEmails))
+
+	fs.StringVar(
+		&args.ChangelogFile,
+		flagChangelogFile,
+		"",
+		fmt.Sprintf(`if set together with -%[1]s, write the changelog to the file instead of the default file of the format (e.g. %[2]s, debian/changelog); e.g. the spec file of a RPM package in incremental mode
+	e.g.:
+	$ ./%[3]s -%[4]s=minor -%[1]s -%[5]s -%[6]s=%[7]s -%[8]s=packaging/my-app.spec
+`,
+			flagChangelog, changelog.DefaultChangelogFile, binaryName, flagIncrement, flagChangelogIncremental, flagChangelogFormat, changelog.FormatRpm, flagChangelogFile))
+
+	fs.BoolVar(
+		&args.ReleaseNotes,
+		flagReleaseNotes,
+		false,
+		fmt.Sprintf(`if set, print the release notes of the new version to stdout instead of the version: the commits since the latest tag (or between the previous tag and the tag of the version if it already exists), rendered like the changelog (see -%[1]s, -%[2]s, -%[3]s); e.g. for the body of a GitHub/GitLab release or the message of an annotated tag
+	e.g.:
+	$ ./%[4]s -%[5]s=minor -%[6]s > notes.md
+`,
+			flagChangelogFormat, flagChangelogTemplate, flagChangelogSection, binaryName, flagIncrement, flagReleaseNotes))
+
+	fs.StringVar(
+		&args.ReleaseNotesFile,
+		flagReleaseNotesFile,
+		"",
+		fmt.Sprintf(`if set, write the release notes of the new version (see -%[1]s) to the file; the version is still printed to stdout
+	e.g.:
+	$ ./%[2]s -%[3]s=minor -%[4]s=RELEASE_NOTES.md
+`,
+			flagReleaseNotes, binaryName, flagIncrement, flagReleaseNotesFile))
+
+	fs.StringVar(
+		&args.GitHostType,
+		flagGitHostType,
+		"",
+		fmt.Sprintf(`if set together with -%[1]s, use the URL patterns of the git host type instead of detecting it from the host name of the remote: %[2]s; use it for self-hosted instances whose host name doesn't contain the host type
+	e.g.:
+	$ ./%[3]s -%[1]s -%[4]s=%[5]s
+`,
+			flagChangelog, strings.Join(remote.HostTypes(), ", "), binaryName, flagGitHostType, remote.HostGitLab))
+
+	fs.StringVar(
+		&args.GitHostUrl,
+		flagGitHostUrl,
+		"",
+		fmt.Sprintf(`if set together with -%[1]s, derive the hyperlinks from the web URL of the repository instead of the URL of the remote %[2]s (e.g. if the remote is a mirror or an SSH alias)
+	e.g.:
+	$ ./%[3]s -%[1]s -%[4]s=https://git.example.com/my_org/my_repository -%[5]s=%[6]s
+`,
+			flagChangelog, changelog.DefaultRemote, binaryName, flagGitHostUrl, flagGitHostType, remote.HostGitea))
+
+}
+
+func (args *CliArgs) loadPackageFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&args.PackageName,
+		flagPackageName,
+		"",
+		fmt.Sprintf(`the name of the (source) package in the changelogs of the formats %[1]s and %[2]s (see -%[3]s); required by these formats
 	e.g.:
-	$ %[1]s="https://gitlab.com/my_org/my_group/my_repository/-/commit/" %[2]s="https://gitlab.com/my_org/my_group/my_repository/-/tags/" ./%s -%s
-	output: a full repository changelog in a file (%s) that shows the commit name(s) included in each tag
+	$ ./%[4]s -%[5]s=minor -%[6]s -%[7]s -%[3]s=%[1]s -%[8]s=my-app -%[9]s="Jane Doe <jane@example.com>" -%[10]s=bookworm
+	output: a new entry at the top of debian/changelog, e.g. "my-app (1.3.0-1) bookworm; urgency=medium"
 `,
-			changelog.EnvVarGitCommitUrl, changelog.EnvVarGitTagUrl, binaryName, flagChangelog, changelog.DefaultChangelogFile))
+			changelog.FormatDebian, changelog.FormatRpm, flagChangelogFormat, binaryName, flagIncrement, flagChangelog, flagChangelogIncremental, flagPackageName, flagPackageMaintainer, flagPackageDistribution))
 
+	fs.StringVar(
+		&args.PackageMaintainer,
+		flagPackageMaintainer,
+		"",
+		fmt.Sprintf("the maintainer of the package with the format: Name <email>; defaults to the environment variables %s and %s", changelog.EnvVarDebFullName, changelog.EnvVarDebEmail))
+
+	fs.StringVar(
+		&args.PackageDistribution,
+		flagPackageDistribution,
+		changelog.DefaultPackageDistribution,
+		fmt.Sprintf("the distribution of the Debian package (e.g. bookworm) in the changelog of the format %s", changelog.FormatDebian))
+
+	fs.StringVar(
+		&args.PackageUrgency,
+		flagPackageUrgency,
+		changelog.DefaultPackageUrgency,
+		fmt.Sprintf("the urgency of the Debian upload in the changelog of the format %s: [ low | medium | high | emergency | critical ]", changelog.FormatDebian))
+
+	fs.StringVar(
+		&args.PackageRelease,
+		flagPackageRelease,
+		changelog.DefaultPackageRelease,
+		fmt.Sprintf("the release of the package, appended to the version in the changelogs of the formats %s and %s (e.g. 1.3.0-1)", changelog.FormatDebian, changelog.FormatRpm))
 }
 
-func (args *CliArgs) loadBaseActionFlags() {
-	flag.BoolVar(
+func (args *CliArgs) loadPushFlags(fs *flag.FlagSet) {
+	fs.BoolVar(
 		&args.Push,
 		flagShouldPush,
 		false,
 		"if set, push the created/updated object(s): push the git tag AND/OR add, commit and push the updated file")
 
-	flag.BoolVar(
+	fs.BoolVar(
+		&args.DryRun,
+		flagDryRun,
+		false,
+		fmt.Sprintf(`if set, compute everything but only print what would be done: the git tag(s), the unified diff of every file change, the git commands (add, commit, push) and the commands of -%s. Nothing is written to the working tree, the git index or the remote, and the tags of the remote are not fetched
+	e.g.:
+	$ ./%s -%s=minor -%s -%s=package.json -%s -%[7]s
+	[dry-run] git tag --annotate "1.3.0"
+	[dry-run] git push origin "1.3.0"
+	--- a/package.json
+	+++ b/package.json
+	@@ -1,3 +1,3 @@
+	 {
+	-  "version": "1.2.3"
+	+  "version": "1.3.0"
+	 }
+	[dry-run] git add package.json
+	[dry-run] git commit -m "chore(version): 1.3.0"
+	[dry-run] git push origin --all
+`,
+			flagExecuteCommand, binaryName, flagIncrement, flagShouldTagGit, flagBumpFile, flagShouldPush, flagDryRun))
+}
+
+func (args *CliArgs) loadBaseActionFlags(fs *flag.FlagSet) {
+	fs.BoolVar(
 		&args.ShouldTagGit,
 		flagShouldTagGit,
 		false,
 		"if set, create an annotated tag")
 
-	flag.StringVar(
+	fs.BoolVar(
+		&args.Verify,
+		flagVerify,
+		false,
+		fmt.Sprintf(`if set, only verify that the latest version tag, the version tag at HEAD (if any) and the version files (-%s, -%s) agree on the current version; if they disagree, print the differences and exit with an error. Nothing is created or updated
+	e.g.:
+	$ ./%s -%s=v -%[1]s=package.json -%[1]s=Chart.yaml:.appVersion -%[5]s
+	--- expected (git tag at HEAD)
+	+++ found
+	  git tag at HEAD: v1.4.0
+	  latest git tag: v1.4.0
+	  package.json: 1.4.0
+	- Chart.yaml:.appVersion: v1.4.0
+	+ Chart.yaml:.appVersion: 1.3.9
+`,
+			flagBumpFile, flagFileName, binaryName, flagPrefix, flagVerify))
+
+	fs.StringVar(
 		&args.ExecuteCommand,
 		flagExecuteCommand,
 		"",
@@ -163,8 +732,49 @@ func (args *CliArgs) loadBaseActionFlags() {
 
 }
 
-func (args *CliArgs) loadGenericVersionFlags() {
-	flag.StringVar(
+func (args *CliArgs) loadIncrementFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&args.VersionScopeAsString,
+		flagIncrement,
+		"",
+		"if set, increment the version scope: [ none | auto | major | minor | patch ]")
+}
+
+func (args *CliArgs) loadGenericVersionFlags(fs *flag.FlagSet) {
+	args.loadPrefixSuffixFlags(fs)
+
+	fs.StringVar(
+		&args.CustomVersion,
+		flagVersion,
+		"",
+		`if set, use the provided version`)
+
+	fs.StringVar(
+		&args.VersionSources,
+		flagVersionSource,
+		"",
+		fmt.Sprintf(`if set, read the current version from the sources in this order of precedence: [ %[1]s | %[2]s | %[3]s | %[4]s ]; the first source that provides a version is used and a warning is logged for every other source that disagrees with it. If not set, the -%[5]s flag is used if provided, otherwise the git tags
+	e.g.:
+	$ ./%[6]s -%[7]s=%[3]s,%[4]s -%[8]s=package.json
+`,
+			version.SourceFlag, version.SourceEnv, version.SourceFile, version.SourceGit, flagVersion, binaryName, flagVersionSource, flagVersionFile))
+
+	fs.StringVar(
+		&args.VersionEnvVar,
+		flagVersionEnvVar,
+		version.DefaultEnvVarVersion,
+		fmt.Sprintf("the environment variable read by the %q version source", version.SourceEnv))
+
+	fs.StringVar(
+		&args.VersionFile,
+		flagVersionFile,
+		"",
+		fmt.Sprintf("the file read by the %q version source, using the same format as -%s; defaults to the first version file provided with -%s or -%s",
+			version.SourceFile, flagBumpFile, flagFileName, flagBumpFile))
+}
+
+func (args *CliArgs) loadPrefixSuffixFlags(fs *flag.FlagSet) {
+	fs.StringVar(
 		&args.Prefix,
 		flagPrefix,
 		"",
@@ -175,7 +785,7 @@ func (args *CliArgs) loadGenericVersionFlags() {
 `,
 			binaryName, flagPrefix))
 
-	flag.StringVar(
+	fs.StringVar(
 		&args.Suffix,
 		flagSuffix,
 		"",
@@ -185,24 +795,131 @@ func (args *CliArgs) loadGenericVersionFlags() {
 	0.1.0-rc
 `,
 			binaryName, flagSuffix))
-
-	flag.StringVar(
-		&args.CustomVersion,
-		flagVersion,
-		"",
-		`if set, use the provided version`)
-
-	flag.StringVar(
-		&args.VersionScopeAsString,
-		flagIncrement,
-		"",
-		"if set, increment the version scope: [ none | auto | major | minor | patch ]")
 }
 
 func (args *CliArgs) guardAgainstInvalidArgs() {
-	if (args.FileName == "") != (args.FileVersionPattern == "") {
+	if args.FileName == "" && (args.FileVersionPattern != "" || args.FileVersionPath != "") {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagFileName, flagFileVersionPattern, flagFileVersionPath},
+		}).Fatalln(errMissingArgs)
+	}
+	if args.FileVersionPattern != "" && args.FileVersionPath != "" {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagFileVersionPattern, flagFileVersionPath},
+		}).Fatalln(errConflictingArgs)
+	}
+	if _, hasPreset := version.FindPreset(args.FileName); args.FileName != "" && args.FileVersionPattern == "" && args.FileVersionPath == "" && !hasPreset {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagFileName, flagFileVersionPattern, flagFileVersionPath},
+		}).Fatalln(errMissingArgs)
+	}
+	if args.GoMajorMigrate && !args.GoModules {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagGoModules, flagGoMajorMigrate},
+		}).Fatalln(errMissingArgs)
+	}
+	if args.GoModules && len(args.Components) > 0 {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagGoModules, flagComponent},
+		}).Fatalln(errConflictingArgs)
+	}
+	if (args.GoVersionFile != "" || args.GoLdflags != "") && (args.GoModules || len(args.Components) > 0) {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagGoVersionFile, flagGoLdflags, flagGoModules, flagComponent},
+		}).Fatalln(errConflictingArgs)
+	}
+	releaseNotes := args.ReleaseNotes || args.ReleaseNotesFile != ""
+	if args.ChangelogIncremental && !args.Changelog {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagChangelog, flagChangelogIncremental},
+		}).Fatalln(errMissingArgs)
+	}
+	if (args.ChangelogTemplate != "" || args.ChangelogFormat != changelog.DefaultFormat) && !args.Changelog && !releaseNotes {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagChangelog, flagReleaseNotes, flagReleaseNotesFile, flagChangelogTemplate, flagChangelogFormat},
+		}).Fatalln(errMissingArgs)
+	}
+	if releaseNotes && args.GoLdflags != "" {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagReleaseNotes, flagReleaseNotesFile, flagGoLdflags},
+		}).Fatalln(errConflictingArgs)
+	}
+	if releaseNotes && (args.GoModules || len(args.Components) > 0 || args.Verify) {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagReleaseNotes, flagReleaseNotesFile, flagGoModules, flagComponent, flagVerify},
+		}).Fatalln(errConflictingArgs)
+	}
+	if args.ChangelogFile != "" && !args.Changelog {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagChangelog, flagChangelogFile},
+		}).Fatalln(errMissingArgs)
+	}
+	if args.ChangelogFile != "" && (args.GoModules || len(args.Components) > 0) {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagChangelogFile, flagGoModules, flagComponent},
+		}).Fatalln(errConflictingArgs)
+	}
+	if (args.GitHostType != "" || args.GitHostUrl != "" || len(args.ChangelogReferences) > 0 || args.ChangelogFooterRefs || args.ChangelogEmails) && !args.Changelog && !releaseNotes {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagChangelog, flagReleaseNotes, flagReleaseNotesFile, flagGitHostType, flagGitHostUrl, flagChangelogReference, flagChangelogFooterRefs, flagChangelogEmails},
+		}).Fatalln(errMissingArgs)
+	}
+	if args.GitHostType != "" {
+		if err := remote.ValidateHostType(args.GitHostType); err != nil {
+			output.Logger().WithField("flag", flagGitHostType).Fatal(err)
+		}
+	}
+	if args.ChangelogTemplate != "" && args.ChangelogFormat != changelog.DefaultFormat {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagChangelogTemplate, flagChangelogFormat},
+		}).Fatalln(errConflictingArgs)
+	}
+	if format, err := changelog.FindFormat(args.ChangelogFormat); err != nil {
+		output.Logger().WithField("flag", flagChangelogFormat).Fatal(err)
+	} else if args.ChangelogIncremental && !format.Incremental {
+		output.Logger().WithFields(logrus.Fields{
+			"flags":  []string{flagChangelogIncremental, flagChangelogFormat},
+			"format": args.ChangelogFormat,
+		}).Fatalln(errConflictingArgs)
+	}
+	// the format has already been validated
+	format, _ := changelog.FindFormat(args.ChangelogFormat)
+	if format.Package && args.PackageName == "" {
+		output.Logger().WithFields(logrus.Fields{
+			"flags":  []string{flagChangelogFormat, flagPackageName},
+			"format": args.ChangelogFormat,
+		}).Fatalln(errMissingArgs)
+	}
+	packageFlags := args.PackageName != "" || args.PackageMaintainer != "" || args.PackageDistribution != changelog.DefaultPackageDistribution || args.PackageUrgency != changelog.DefaultPackageUrgency || args.PackageRelease != changelog.DefaultPackageRelease
+	if packageFlags && !format.Package {
+		output.Logger().WithFields(logrus.Fields{
+			"flags":  []string{flagChangelogFormat, flagPackageName, flagPackageMaintainer, flagPackageDistribution, flagPackageUrgency, flagPackageRelease},
+			"format": args.ChangelogFormat,
+		}).Fatalln(errMissingArgs)
+	}
+	if args.Output != OutputText && args.Output != OutputJson {
+		output.Logger().WithFields(logrus.Fields{
+			"flag":    flagOutput,
+			"output":  args.Output,
+			"outputs": []string{OutputText, OutputJson},
+		}).Fatalln(errUnknownOutput)
+	}
+	if args.Output == OutputJson && (args.Verify || args.GoLdflags != "") {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagOutput, flagVerify, flagGoLdflags},
+		}).Fatalln(errConflictingArgs)
+	}
+	if args.LintFrom != "" && args.LintMessageFile != "" {
+		output.Logger().WithFields(logrus.Fields{
+			"flags": []string{flagLintFrom, flagLintMessageFile},
+		}).Fatalln(errConflictingArgs)
+	}
+	if _, err := component.ParseDependencyScope(args.ComponentDependencyScopeAsString); err != nil {
+		output.Logger().WithField("flag", flagComponentDependencyIncrement).Fatal(err)
+	}
+	if len(args.ComponentDependencies) > 0 && len(args.Components) == 0 {
 		output.Logger().WithFields(logrus.Fields{
-			"flags": []string{flagFileName, flagFileVersionPattern},
+			"flags": []string{flagComponent, flagComponentDependency},
 		}).Fatalln(errMissingArgs)
 	}
 }
@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+)
+
+const (
+	CommandCurrent   = "current"
+	CommandNext      = "next"
+	CommandTag       = "tag"
+	CommandBumpFile  = "bump-file"
+	CommandChangelog = "changelog"
+	CommandLint      = "lint"
+	CommandVerify    = "verify"
+)
+
+// command is a subcommand of the CLI: it accepts only its own flags and it performs a single job
+type command struct {
+	name        string
+	description string
+	// flags registers the flags accepted by the command
+	flags func(args *CliArgs, fs *flag.FlagSet)
+	// implied sets the arguments implied by the command (e.g. tag implies -git-tag) after the flags are parsed
+	implied func(args *CliArgs)
+	// fetch is true if the command fetches the tags of the remote by default; the read-only commands accept -fetch instead
+	fetch bool
+}
+
+/*
+commands are the subcommands of the CLI, in the order of the help
+  - current, next, lint and verify are read-only: the local tags are read without fetching the remote (unless -fetch is set, for the commands that accept it) and nothing is written
+  - the other commands perform the same actions as the equivalent flags of the flag-only invocation
+*/
+var commands = []command{
+	{
+		name:        CommandCurrent,
+		description: "print the current version: the latest version tag, or the version read from the version sources",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadGenericVersionFlags(fs)
+			args.loadFetchFlags(fs)
+			args.loadOutputFlags(fs)
+		},
+	},
+	{
+		name:        CommandNext,
+		description: "print the next version computed from the commits since the latest version tag, without creating or updating anything",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadGenericVersionFlags(fs)
+			args.loadIncrementFlags(fs)
+			args.loadFetchFlags(fs)
+			args.loadOutputFlags(fs)
+		},
+		implied: defaultIncrement,
+	},
+	{
+		name:        CommandTag,
+		fetch:       true,
+		description: "create the git tag of the next version (or the tags of the released components of a monorepo) and print it",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadGenericVersionFlags(fs)
+			args.loadIncrementFlags(fs)
+			args.loadTagFlags(fs)
+			args.loadPushFlags(fs)
+			args.loadComponentFlags(fs)
+			args.loadOutputFlags(fs)
+		},
+		implied: func(args *CliArgs) {
+			defaultIncrement(args)
+			args.ShouldTagGit = true
+		},
+	},
+	{
+		name:        CommandBumpFile,
+		fetch:       true,
+		description: "update the version in the version files with a single commit and print the version",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadGenericVersionFlags(fs)
+			args.loadIncrementFlags(fs)
+			args.loadFileActionFlags(fs)
+			args.loadBumpFileFlags(fs)
+			args.loadGoBuildFlags(fs)
+			args.loadPushFlags(fs)
+			args.loadOutputFlags(fs)
+		},
+		implied: defaultIncrement,
+	},
+	{
+		name:        CommandChangelog,
+		fetch:       true,
+		description: "generate the changelog of the repository (or print the release notes of the next version) and print the version",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadGenericVersionFlags(fs)
+			args.loadIncrementFlags(fs)
+			args.loadChangelogFlags(fs)
+			args.loadPackageFlags(fs)
+			args.loadPushFlags(fs)
+			args.loadOutputFlags(fs)
+		},
+		implied: func(args *CliArgs) {
+			defaultIncrement(args)
+			if !args.ReleaseNotes && args.ReleaseNotesFile == "" {
+				args.Changelog = true
+			}
+		},
+	},
+	{
+		name:        CommandLint,
+		description: "check that the commit messages since the latest version tag follow the Conventional Commits specification; the violations are printed and the command fails if there are any",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadPrefixSuffixFlags(fs)
+			args.loadLintFlags(fs)
+			args.loadFetchFlags(fs)
+		},
+	},
+	{
+		name:        CommandVerify,
+		description: "check that the latest version tag, the version tag at HEAD (if any) and the version files agree on the current version; the differences are printed and the command fails if they disagree, e.g. as a CI gate",
+		flags: func(args *CliArgs, fs *flag.FlagSet) {
+			args.loadPrefixSuffixFlags(fs)
+			args.loadFileActionFlags(fs)
+			args.loadBumpFileFlags(fs)
+		},
+		implied: func(args *CliArgs) {
+			args.Verify = true
+		},
+	},
+}
+
+// defaultIncrement increments the version automatically from the commit messages if no scope is provided
+func defaultIncrement(args *CliArgs) {
+	if args.VersionScopeAsString == "" {
+		args.VersionScopeAsString = "auto"
+	}
+}
+
+// findCommand returns the command with the provided name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// flagSet returns the flag set of the command: its own flags and the configuration file; the fetch default of the command is set before the flags are registered
+func (cmd command) flagSet(args *CliArgs, errorHandling flag.ErrorHandling) *flag.FlagSet {
+	fs := flag.NewFlagSet(binaryName+" "+cmd.name, errorHandling)
+	fs.Usage = cmd.usage(fs)
+	args.Fetch = cmd.fetch
+	cmd.flags(args, fs)
+	args.loadConfigFlags(fs)
+	return fs
+}
+
+// commandNames returns the names of the commands
+func commandNames() []string {
+	var names []string
+	for _, cmd := range commands {
+		names = append(names, cmd.name)
+	}
+	return names
+}
+
+// usage returns the help of the command: its description and its flags
+func (cmd command) usage(fs *flag.FlagSet) func() {
+	return func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage: %s %s [flags]\n\n%s\n\n%s\n\nFlags:\n", binaryName, cmd.name, cmd.description, envUsage())
+		fs.PrintDefaults()
+	}
+}
+
+// usage prints the help of the CLI: the commands, then the flags of the flag-only invocation
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage:\n  %[1]s <command> [flags]\n  %[1]s [flags]\n\nCommands:\n", binaryName)
+	width := 0
+	for _, name := range commandNames() {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-*s  %s\n", width, cmd.name, cmd.description)
+	}
+	fmt.Fprintf(out, "\nRun '%s <command> -h' for the flags of a command.\n\n%s\n\nFlags (without a command, all the flags are accepted):\n", binaryName, envUsage())
+	flag.PrintDefaults()
+}
+
+// envUsage describes the environment variables bound to the flags and the precedence of the sources of the flags
+func envUsage() string {
+	return fmt.Sprintf(`Every flag can also be set with the environment variable %[1]s<FLAG>: the name of the flag in upper case with underscores (e.g. %[2]s for -%[3]s); a repeatable flag has one value per line.
+The value of a flag is taken from, in this order of precedence: the command line, the environment variable, the configuration file (-%[4]s), the default value.`,
+		envVarPrefix, EnvVarName(flagBumpFile), flagBumpFile, flagConfig)
+}
@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"semtag/pkg/config"
+	"semtag/pkg/versionControl"
+)
+
+func Test_FindCommand(t *testing.T) {
+	// arrange
+	tables := []struct {
+		name string
+
+		want bool
+	}{
+		{CommandCurrent, true},
+		{CommandNext, true},
+		{CommandTag, true},
+		{CommandBumpFile, true},
+		{CommandChangelog, true},
+		{CommandLint, true},
+		{CommandVerify, true},
+		{"-increment=patch", false},
+		{"release", false},
+		{"", false},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("name=%q", tb.name), func(t *testing.T) {
+			cmd, ok := findCommand(tb.name)
+
+			// assert
+			if ok != tb.want {
+				t.Errorf("got %t want %t", ok, tb.want)
+			}
+			if ok && cmd.name != tb.name {
+				t.Errorf("got command %q want %q", cmd.name, tb.name)
+			}
+		})
+	}
+}
+
+func Test_CommandFlagSet(t *testing.T) {
+	// arrange
+	tables := []struct {
+		command string
+
+		wantFlags    []string
+		missingFlags []string
+	}{
+		{CommandCurrent, []string{flagPrefix, flagFetch, flagOutput, flagConfig}, []string{flagIncrement, flagShouldTagGit, flagShouldPush}},
+		{CommandNext, []string{flagIncrement, flagFetch, flagOutput}, []string{flagShouldTagGit, flagShouldPush, flagDryRun, flagChangelog}},
+		{CommandTag, []string{flagIncrement, flagPath, flagShouldPush, flagDryRun, flagComponent}, []string{flagFetch, flagShouldTagGit, flagChangelog, flagBumpFile}},
+		{CommandBumpFile, []string{flagBumpFile, flagGoVersionFile, flagShouldPush}, []string{flagFetch, flagShouldTagGit, flagChangelog}},
+		{CommandChangelog, []string{flagChangelogFormat, flagReleaseNotes, flagPackageName}, []string{flagFetch, flagShouldTagGit, flagBumpFile}},
+		{CommandLint, []string{flagPrefix, flagLintTypes, flagFetch}, []string{flagIncrement, flagOutput, flagShouldPush}},
+		{CommandVerify, []string{flagPrefix, flagSuffix, flagBumpFile, flagFileName, flagFileVersionPath, flagConfig}, []string{flagFetch, flagIncrement, flagOutput, flagShouldTagGit, flagShouldPush}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.command, func(t *testing.T) {
+			cmd, _ := findCommand(tb.command)
+			fs := cmd.flagSet(&CliArgs{}, flag.ContinueOnError)
+
+			// assert
+			for _, name := range tb.wantFlags {
+				if fs.Lookup(name) == nil {
+					t.Errorf("got no flag -%s want it to be accepted", name)
+				}
+			}
+			for _, name := range tb.missingFlags {
+				if fs.Lookup(name) != nil {
+					t.Errorf("got flag -%s want it to be rejected", name)
+				}
+			}
+		})
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	// arrange
+	config.GitRepo = &versionControl.GitRepositoryMock{}
+	tables := []struct {
+		arguments []string
+
+		wantCommand   string
+		wantFetch     bool
+		wantTagGit    bool
+		wantChangelog bool
+		wantIncrement string
+		wantVerify    bool
+	}{
+		{[]string{CommandCurrent}, CommandCurrent, false, false, false, "", false},
+		{[]string{CommandNext}, CommandNext, false, false, false, "auto", false},
+		{[]string{CommandNext, "-fetch", "-increment=minor"}, CommandNext, true, false, false, "minor", false},
+		{[]string{CommandTag}, CommandTag, true, true, false, "auto", false},
+		{[]string{CommandTag, "-dry-run"}, CommandTag, false, true, false, "auto", false},
+		{[]string{CommandBumpFile, "-bump-file=VERSION"}, CommandBumpFile, true, false, false, "auto", false},
+		{[]string{CommandChangelog}, CommandChangelog, true, false, true, "auto", false},
+		{[]string{CommandChangelog, "-release-notes"}, CommandChangelog, true, false, false, "auto", false},
+		{[]string{CommandLint}, CommandLint, false, false, false, "", false},
+		{[]string{CommandVerify, "-prefix=v", "-bump-file=VERSION"}, CommandVerify, false, false, false, "", true},
+		{[]string{"-increment=patch"}, "", true, false, false, "patch", false},
+		{[]string{"-increment=patch", "-git-tag", "-changelog"}, "", true, true, true, "patch", false},
+		{[]string{"-increment=patch", "-dry-run"}, "", false, false, false, "patch", false},
+		{[]string{"-verify", "-bump-file=VERSION"}, "", true, false, false, "", true},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(strings.Join(tb.arguments, " "), func(t *testing.T) {
+			args := CliArgs{}
+			args.parse(flag.NewFlagSet(binaryName, flag.ContinueOnError), tb.arguments)
+
+			// assert
+			if args.Command != tb.wantCommand {
+				t.Errorf("got command %q want %q", args.Command, tb.wantCommand)
+			}
+			if args.Fetch != tb.wantFetch {
+				t.Errorf("got fetch %t want %t", args.Fetch, tb.wantFetch)
+			}
+			if args.ShouldTagGit != tb.wantTagGit {
+				t.Errorf("got git tag %t want %t", args.ShouldTagGit, tb.wantTagGit)
+			}
+			if args.Changelog != tb.wantChangelog {
+				t.Errorf("got changelog %t want %t", args.Changelog, tb.wantChangelog)
+			}
+			if args.VersionScopeAsString != tb.wantIncrement {
+				t.Errorf("got increment %q want %q", args.VersionScopeAsString, tb.wantIncrement)
+			}
+			if args.Verify != tb.wantVerify {
+				t.Errorf("got verify %t want %t", args.Verify, tb.wantVerify)
+			}
+		})
+	}
+}
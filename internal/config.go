@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/config"
+	"semtag/pkg/output"
+)
+
+// configFlags maps the keys of the configuration file to the flags they replace
+var configFlags = map[string]string{
+	"prefix":                         flagPrefix,
+	"suffix":                         flagSuffix,
+	"paths":                          flagPath,
+	"commits.increment":              flagIncrement,
+	"commits.types":                  flagLintTypes,
+	"commits.dependencyIncrement":    flagComponentDependencyIncrement,
+	"files":                          flagBumpFile,
+	"changelog.enabled":              flagChangelog,
+	"changelog.format":               flagChangelogFormat,
+	"changelog.template":             flagChangelogTemplate,
+	"changelog.file":                 flagChangelogFile,
+	"changelog.regex":                flagChangelogRegex,
+	"changelog.incremental":          flagChangelogIncremental,
+	"changelog.sections":             flagChangelogSection,
+	"changelog.references":           flagChangelogReference,
+	"changelog.footerReferences":     flagChangelogFooterRefs,
+	"changelog.emails":               flagChangelogEmails,
+	"changelog.hostType":             flagGitHostType,
+	"changelog.hostUrl":              flagGitHostUrl,
+	"changelog.package.name":         flagPackageName,
+	"changelog.package.maintainer":   flagPackageMaintainer,
+	"changelog.package.distribution": flagPackageDistribution,
+	"changelog.package.urgency":      flagPackageUrgency,
+	"changelog.package.release":      flagPackageRelease,
+	"components":                     flagComponent,
+	"components.dependsOn":           flagComponentDependency,
+	"hooks.command":                  flagExecuteCommand,
+}
+
+// configExample is the configuration file of the help of -config
+const configExample = `prefix: v
+commits:
+  increment: auto
+files:
+  - package.json
+  - Chart.yaml:.appVersion
+changelog:
+  enabled: true
+  format: keep-a-changelog
+`
+
+func (args *CliArgs) loadConfigFlags(fs *flag.FlagSet) {
+	fs.StringVar(
+		&args.ConfigFile,
+		flagConfig,
+		"",
+		fmt.Sprintf(`the repository configuration file; defaults to the file %[1]s at the root of the repository, if it exists. Its settings replace the flags, and the flags provided on the command line override them; the schema is described in docs/configuration.md
+	e.g.:
+	$ cat %[1]s
+%[4]s	$ ./%[2]s tag -%[3]s
+`,
+			config.DefaultFile, binaryName, flagShouldPush, indent(configExample, "\t\t")))
+}
+
+// indent prefixes every line of the text
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(strings.TrimSuffix(text, "\n"), "\n", "\n"+prefix) + "\n"
+}
+
+/*
+applyConfig sets the flags from the settings of the configuration file
+  - the flags provided on the command line or with an environment variable are not overridden
+  - the settings of the flags that the command doesn't accept are ignored (e.g. the changelog settings for the next command)
+  - without a command, the changelog settings are applied only if the changelog or the release notes are generated
+*/
+func (args *CliArgs) applyConfig(fs *flag.FlagSet) {
+	path, err := config.Find(args.ConfigFile)
+	if err != nil {
+		output.Logger().WithField("flag", flagConfig).Fatal(err)
+	}
+	if path == "" {
+		return
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		output.Logger().WithField("flag", flagConfig).Fatal(err)
+	}
+
+	var applied []string
+	// a repeatable flag has several settings (e.g. files)
+	applying := map[string]bool{}
+	for _, s := range cfg.Settings() {
+		name := configFlags[s.Key]
+		if fs.Lookup(name) == nil || (args.Sources[name] != "" && !applying[name]) {
+			continue
+		}
+		// changelog.enabled is the first changelog setting: it is applied before the others are checked
+		if args.Command == "" && strings.HasPrefix(s.Key, "changelog.") && s.Key != "changelog.enabled" && !args.Changelog && !args.ReleaseNotes && args.ReleaseNotesFile == "" {
+			continue
+		}
+		if err := fs.Set(name, s.Value); err != nil {
+			output.Logger().WithField("flag", flagConfig).Fatal(fmt.Errorf("%v: %s:%d: %s: %v", config.ErrInvalidConfig, path, s.Line, s.Key, err))
+		}
+		if !applying[name] {
+			applying[name] = true
+			args.Sources[name] = fmt.Sprintf("%s:%s:%d", sourceConfig, path, s.Line)
+			applied = append(applied, name)
+		}
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"configFile":  path,
+		"configFlags": applied,
+	}).Info("configuration file loaded")
+}
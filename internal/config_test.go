@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"semtag/pkg/changelog"
+	"semtag/pkg/config"
+	"semtag/pkg/version"
+)
+
+func Test_ConfigExample(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), config.DefaultFile)
+	if err := ioutil.WriteFile(path, []byte(configExample), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err := config.Load(path)
+
+	// assert
+	if err != nil {
+		t.Errorf("got %v: the example of the help must be a valid configuration file", err)
+	}
+}
+
+func Test_ApplyConfig(t *testing.T) {
+	// arrange
+	contents := `prefix: v
+commits:
+  increment: minor
+files:
+  - VERSION
+  - package.json
+changelog:
+  format: keep-a-changelog
+`
+	enabledContents := "changelog:\n  enabled: true\n  format: json\n"
+	tables := []struct {
+		name      string
+		command   string
+		arguments []string
+		contents  string
+
+		wantPrefix    string
+		wantIncrement string
+		wantFiles     version.Files
+		wantFormat    string
+		wantSources   map[string]string
+	}{
+		{"config", "", nil, contents,
+			"v", "minor", version.Files{{Path: "VERSION"}, {Path: "package.json"}}, changelog.DefaultFormat,
+			map[string]string{flagPrefix: "config:%s:1", flagIncrement: "config:%s:3", flagBumpFile: "config:%s:5"}},
+		{"flag over config", "", []string{"-prefix=release-", "-bump-file=Chart.yaml"}, contents,
+			"release-", "minor", version.Files{{Path: "Chart.yaml"}}, changelog.DefaultFormat,
+			map[string]string{flagPrefix: sourceFlag, flagIncrement: "config:%s:3", flagBumpFile: sourceFlag}},
+		{"changelog flag", "", []string{"-changelog"}, contents,
+			"v", "minor", version.Files{{Path: "VERSION"}, {Path: "package.json"}}, changelog.FormatKeepAChangelog,
+			map[string]string{flagChangelog: sourceFlag, flagPrefix: "config:%s:1", flagIncrement: "config:%s:3", flagBumpFile: "config:%s:5", flagChangelogFormat: "config:%s:8"}},
+		{"release notes flag", "", []string{"-release-notes"}, contents,
+			"v", "minor", version.Files{{Path: "VERSION"}, {Path: "package.json"}}, changelog.FormatKeepAChangelog,
+			map[string]string{flagReleaseNotes: sourceFlag, flagPrefix: "config:%s:1", flagIncrement: "config:%s:3", flagBumpFile: "config:%s:5", flagChangelogFormat: "config:%s:8"}},
+		{"changelog enabled", "", nil, enabledContents,
+			"", "", nil, changelog.FormatJson,
+			map[string]string{flagChangelog: "config:%s:2", flagChangelogFormat: "config:%s:3"}},
+		{"next command", CommandNext, []string{"-increment=patch"}, contents,
+			"v", "patch", nil, changelog.DefaultFormat,
+			map[string]string{flagPrefix: "config:%s:1", flagIncrement: sourceFlag}},
+		{"changelog command", CommandChangelog, nil, contents,
+			"v", "minor", nil, changelog.FormatKeepAChangelog,
+			map[string]string{flagPrefix: "config:%s:1", flagIncrement: "config:%s:3", flagChangelogFormat: "config:%s:8"}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), config.DefaultFile)
+			if err := ioutil.WriteFile(path, []byte(tb.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			args := CliArgs{Command: tb.command}
+			fs := flag.NewFlagSet(binaryName, flag.ContinueOnError)
+			if cmd, ok := findCommand(tb.command); ok {
+				args.loadAllFlags(flag.NewFlagSet(cmd.name, flag.ContinueOnError))
+				fs = cmd.flagSet(&args, flag.ContinueOnError)
+			} else {
+				args.loadAllFlags(fs)
+			}
+			if err := fs.Parse(append(tb.arguments, "-config="+path)); err != nil {
+				t.Fatal(err)
+			}
+			args.Sources = map[string]string{}
+			fs.Visit(func(f *flag.Flag) {
+				if f.Name != flagConfig {
+					args.Sources[f.Name] = sourceFlag
+				}
+			})
+			wantSources := map[string]string{}
+			for name, source := range tb.wantSources {
+				wantSources[name] = strings.ReplaceAll(source, "%s", path)
+			}
+
+			args.applyConfig(fs)
+
+			// assert
+			if args.Prefix != tb.wantPrefix || args.VersionScopeAsString != tb.wantIncrement {
+				t.Errorf("got prefix=%q, increment=%q want %q and %q", args.Prefix, args.VersionScopeAsString, tb.wantPrefix, tb.wantIncrement)
+			}
+			if !reflect.DeepEqual(args.BumpFiles, tb.wantFiles) {
+				t.Errorf("got files %+v want %+v", args.BumpFiles, tb.wantFiles)
+			}
+			if args.ChangelogFormat != tb.wantFormat {
+				t.Errorf("got changelog format %q want %q", args.ChangelogFormat, tb.wantFormat)
+			}
+			if !reflect.DeepEqual(args.Sources, wantSources) {
+				t.Errorf("got sources %v want %v", args.Sources, wantSources)
+			}
+		})
+	}
+}
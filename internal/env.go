@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"semtag/pkg/output"
+)
+
+const (
+	// envVarPrefix is the prefix of the environment variables bound to the flags (e.g. SEMTAG_BUMP_FILE for -bump-file)
+	envVarPrefix = "SEMTAG_"
+
+	// the sources of the value of a flag, shown in the logs
+	sourceFlag   = "flag"
+	sourceEnv    = "env"
+	sourceConfig = "config"
+)
+
+// repeatableFlags are the flags that can be repeated; their environment variables contain one value per line
+var repeatableFlags = map[string]bool{
+	flagPath:                true,
+	flagBumpFile:            true,
+	flagChangelogSection:    true,
+	flagChangelogReference:  true,
+	flagComponent:           true,
+	flagComponentDependency: true,
+}
+
+// EnvVarName returns the name of the environment variable bound to a flag: the upper case name of the flag with the prefix SEMTAG_ and the dashes replaced with underscores
+func EnvVarName(flagName string) string {
+	return envVarPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+/*
+bind sets the flags that are not provided on the command line, in this order of precedence:
+  - the command line flags
+  - the environment variables (e.g. SEMTAG_INCREMENT=auto)
+  - the settings of the configuration file
+  - the default values of the flags
+
+the source of every flag that doesn't have its default value is recorded in Sources
+*/
+func (args *CliArgs) bind(fs *flag.FlagSet) {
+	args.Sources = map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		args.Sources[f.Name] = sourceFlag
+	})
+	args.applyEnv(fs)
+	args.applyConfig(fs)
+}
+
+// applyEnv sets the flags that are not provided on the command line from their environment variables; the empty variables are ignored
+func (args *CliArgs) applyEnv(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		name := EnvVarName(f.Name)
+		value := os.Getenv(name)
+		if value == "" || args.Sources[f.Name] != "" {
+			return
+		}
+		values := []string{value}
+		if repeatableFlags[f.Name] {
+			values = nil
+			for _, v := range strings.Split(value, "\n") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+		}
+		for _, v := range values {
+			if err := fs.Set(f.Name, v); err != nil {
+				output.Logger().WithField("envVar", name).Fatal(fmt.Errorf("%v: %s=%q: %v", errInvalidEnvVar, name, v, err))
+			}
+		}
+		args.Sources[f.Name] = sourceEnv + ":" + name
+	})
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"semtag/pkg/version"
+)
+
+func Test_EnvVarName(t *testing.T) {
+	// arrange
+	tables := []struct {
+		flagName string
+
+		want string
+	}{
+		{flagPrefix, "SEMTAG_PREFIX"},
+		{flagBumpFile, "SEMTAG_BUMP_FILE"},
+		{flagChangelogFooterRefs, "SEMTAG_CHANGELOG_FOOTER_REFERENCES"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("flag=%s", tb.flagName), func(t *testing.T) {
+			got := EnvVarName(tb.flagName)
+
+			// assert
+			if got != tb.want {
+				t.Errorf("got %s want %s", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_ApplyEnv(t *testing.T) {
+	// arrange
+	env := map[string]string{
+		"SEMTAG_PREFIX":    "v",
+		"SEMTAG_INCREMENT": "major",
+		"SEMTAG_SUFFIX":    "",
+		"SEMTAG_BUMP_FILE": "package.json\n\n  Chart.yaml:.appVersion\n",
+	}
+	for k, v := range env {
+		os.Setenv(k, v)
+		defer os.Unsetenv(k)
+	}
+	args := CliArgs{}
+	fs := flag.NewFlagSet(binaryName, flag.ContinueOnError)
+	args.loadGenericVersionFlags(fs)
+	args.loadIncrementFlags(fs)
+	args.loadBumpFileFlags(fs)
+	if err := fs.Parse([]string{"-increment=patch"}); err != nil {
+		t.Fatal(err)
+	}
+	args.Sources = map[string]string{flagIncrement: sourceFlag}
+	wantFiles := version.Files{{Path: "package.json"}, {Path: "Chart.yaml", VersionPath: ".appVersion"}}
+	wantSources := map[string]string{
+		flagIncrement: sourceFlag,
+		flagPrefix:    "env:SEMTAG_PREFIX",
+		flagBumpFile:  "env:SEMTAG_BUMP_FILE",
+	}
+
+	// act
+	args.applyEnv(fs)
+
+	// assert
+	if args.Prefix != "v" || args.VersionScopeAsString != "patch" || args.Suffix != "" {
+		t.Errorf("got prefix=%q, increment=%q, suffix=%q want v, patch and an empty suffix", args.Prefix, args.VersionScopeAsString, args.Suffix)
+	}
+	if !reflect.DeepEqual(args.BumpFiles, wantFiles) {
+		t.Errorf("got %+v want %+v", args.BumpFiles, wantFiles)
+	}
+	if !reflect.DeepEqual(args.Sources, wantSources) {
+		t.Errorf("got %v want %v", args.Sources, wantSources)
+	}
+}
@@ -3,12 +3,20 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"semtag/internal"
 	"semtag/pkg/changelog"
+	"semtag/pkg/component"
+	"semtag/pkg/conventionalCommit"
+	"semtag/pkg/goModule"
 	"semtag/pkg/output"
+	"semtag/pkg/release"
 	"semtag/pkg/terminal"
 	"semtag/pkg/version"
 	"semtag/pkg/versionControl"
@@ -16,21 +24,77 @@ import (
 
 var (
 	ErrNotPushMode = errors.New("push to git skipped: use the `-push` flag to push changes")
+	ErrLint        = errors.New("the commit messages don't follow the Conventional Commits specification")
 )
 
 var GitRepo versionControl.VersionControl = &versionControl.GitRepository{}
 
+// stdout receives the results of the run; with the JSON output, they are written to stderr so that stdout contains only the JSON document
+var stdout io.Writer = os.Stdout
+
+// actions are the actions performed during the run, reported in the JSON release plan
+var actions []release.Action
+
 func main() {
 	args := internal.CliArgs{}
 	args.ParseFlags()
+	version.FetchTags = args.Fetch
+	if args.Output == internal.OutputJson {
+		stdout = os.Stderr
+	}
 
-	v := setVersion(args)
+	if args.Command == internal.CommandLint {
+		lintCommits(args)
+		return
+	}
+	if args.Verify {
+		verifyVersions(args)
+		return
+	}
+	if args.GoModules {
+		releaseGoModules(args)
+		return
+	}
+	if len(args.Components) > 0 {
+		releaseComponents(args)
+		return
+	}
 
-	// print the version to stdout; execute as the last command so that it can be grepped by simple shell scripts
-	versionNumber := v.RemovePrefixAndSuffix(v.String())
-	defer fmt.Print(versionNumber)
+	previous, v := setVersion(args)
 
-	if args.Push {
+	// print the version (or the Go linker flags) to stdout; execute as the last command so that it can be grepped by simple shell scripts
+	result := v.RemovePrefixAndSuffix(v.String())
+	var info goModule.BuildInfo
+	if args.GoLdflags != "" || args.GoVersionFile != "" {
+		var err error
+		if info, err = buildInfo(v); err != nil {
+			output.Logger().Fatal(err)
+		}
+	}
+	if args.GoLdflags != "" {
+		result = info.Ldflags(args.GoLdflags)
+	}
+	// the release notes are rendered before the version is tagged, so that they contain the commits since the latest tag
+	var notes string
+	if args.ReleaseNotes || args.ReleaseNotesFile != "" {
+		chLog := newChangelog(args, v)
+		var err error
+		if notes, err = chLog.RenderRelease(); err != nil {
+			output.Logger().Fatal(err)
+		}
+		if args.ReleaseNotesFile == "" {
+			result = notes
+		}
+	}
+	if args.Output == internal.OutputJson {
+		// the release is described before the version is tagged, so that it contains the commits since the previous tag
+		r := newRepositoryRelease(args, previous, v)
+		defer printPlan(args, r, notes)
+	} else {
+		defer fmt.Fprint(stdout, result)
+	}
+
+	if args.Push && !args.DryRun {
 		if err := versionControl.TrySetGitCredentialsBasicAuth(); err != nil {
 			output.Logger().Debug(err)
 		}
@@ -38,10 +102,12 @@ func main() {
 
 	if args.ExecuteCommand != "" {
 		for _, val := range v.AsList() {
-			_, err := terminal.Shellf(args.ExecuteCommand, val)
-			if err != nil {
+			if args.DryRun {
+				printDryRun(args.ExecuteCommand, val)
+			} else if _, err := terminal.Shellf(args.ExecuteCommand, val); err != nil {
 				output.Logger().Fatal(err)
 			}
+			recordAction(release.ActionCommand, fmt.Sprintf(args.ExecuteCommand, val), false)
 		}
 	}
 
@@ -55,7 +121,7 @@ func main() {
 			tag := &versionControl.Tag{
 				Name: v.String(),
 			}
-			if err := TagGit(tag, args.Push); err != nil {
+			if err := TagGit(tag, args.Push, args.DryRun); err != nil {
 				output.Logger().Fatal(err)
 			}
 			if !args.Push {
@@ -64,9 +130,18 @@ func main() {
 		}
 	}
 
-	shouldTagInFile := len(args.FileName) > 0 && len(args.FileVersionPattern) > 0
+	files := args.VersionFiles()
+	var generated version.Changes
+	if args.GoVersionFile != "" {
+		change, err := info.VersionFileChange(args.GoVersionFile)
+		if err != nil {
+			output.Logger().Fatal(err)
+		}
+		generated = append(generated, change)
+	}
+	shouldTagInFile := len(files) > 0 || len(generated) > 0
 	if shouldTagInFile {
-		if err := TagFile(v, args.FileName, args.FileVersionPattern, args.Push); err != nil {
+		if err := TagFiles(v, files, generated, args.Push, args.DryRun); err != nil {
 			output.Logger().Fatal(err)
 		}
 		if !args.Push {
@@ -74,28 +149,172 @@ func main() {
 		}
 	}
 
+	if args.ReleaseNotesFile != "" {
+		if err := writeReleaseNotes(args.ReleaseNotesFile, notes, args.DryRun); err != nil {
+			output.Logger().Fatal(err)
+		}
+	}
+
 	if args.Changelog {
-		chLog, err := changelog.NewLog()
-		if err != nil {
+		chLog := newChangelog(args, v)
+		if err := writeChangelog(chLog, args.DryRun); err != nil {
 			output.Logger().Fatal(err)
 		}
-		chLog.Prefix = args.Prefix
-		chLog.Suffix = args.Suffix
-		chLog.Regex = args.ChangelogRegex
+	}
+
+}
+
+// newChangelog configures the changelog of the version from the command line flags; it is also used to render the release notes
+func newChangelog(args internal.CliArgs, v version.Version) changelog.Log {
+	chLog := changelog.NewLog()
+	chLog.Prefix = args.Prefix
+	chLog.Suffix = args.Suffix
+	chLog.Regex = args.ChangelogRegex
+	chLog.Sections = args.ChangelogSections
+	chLog.Incremental = args.ChangelogIncremental
+	chLog.Version = v.String()
+	chLog.TemplateFile = args.ChangelogTemplate
+	chLog.Format = args.ChangelogFormat
+	chLog.HostType = args.GitHostType
+	chLog.HostUrl = args.GitHostUrl
+	chLog.References = changelog.References{Patterns: args.ChangelogReferences, Footers: args.ChangelogFooterRefs}
+	chLog.Emails = args.ChangelogEmails
+	chLog.Package = changelog.Package{
+		Name:         args.PackageName,
+		Maintainer:   args.PackageMaintainer,
+		Distribution: args.PackageDistribution,
+		Urgency:      args.PackageUrgency,
+		Release:      args.PackageRelease,
+	}
+	if args.ChangelogFile != "" {
+		chLog.SetFile(args.ChangelogFile)
+	}
+	return chLog
+}
+
+// newComponentChangelog configures the changelog of a released component: only the commits that changed its paths between its own tags are included, and the changelog file is in its first path (e.g. services/api/CHANGELOG.md)
+func newComponentChangelog(args internal.CliArgs, r componentRelease) changelog.Log {
+	chLog := newChangelog(args, r.Version)
+	chLog.Prefix = r.Component.Prefix
+	chLog.Paths = r.Component.Paths
+	if len(r.Component.Paths) > 0 {
+		chLog.Dir = r.Component.Paths[0]
+	}
+	return chLog
+}
+
+// writeChangelog generates the changelog and writes it to its file; in dry-run mode, the difference with the existing file is printed instead
+func writeChangelog(chLog changelog.Log, dryRun bool) error {
+	if !dryRun {
 		if err := chLog.Generate(); err != nil {
+			return err
+		}
+		recordAction(release.ActionChangelog, chLog.FileName(), false)
+		return nil
+	}
+	contents, err := chLog.Render()
+	if err != nil {
+		return err
+	}
+	current := version.File{Path: chLog.FileName()}
+	old, _ := current.Read()
+	fmt.Fprint(stdout, version.Changes{{Path: chLog.FileName(), Old: string(old), New: contents}}.Diff())
+	recordAction(release.ActionChangelog, chLog.FileName(), false)
+	return nil
+}
+
+// writeReleaseNotes writes the release notes to a file; in dry-run mode, the difference with the existing file is printed instead
+func writeReleaseNotes(path, notes string, dryRun bool) error {
+	f := version.File{Path: path}
+	if dryRun {
+		old, _ := f.Read()
+		fmt.Fprint(stdout, version.Changes{{Path: path, Old: string(old), New: notes}}.Diff())
+		recordAction(release.ActionReleaseNotes, path, false)
+		return nil
+	}
+	if err := f.Write(notes); err != nil {
+		return err
+	}
+	recordAction(release.ActionReleaseNotes, path, false)
+	output.Logger().WithField("releaseNotesFile", path).Info("release notes written")
+	return nil
+}
+
+// verifyVersions checks if the git tags and the version files agree on the current version; the differences are printed to stdout
+func verifyVersions(args internal.CliArgs) {
+	report, err := version.Verify(args.Prefix, args.Suffix, args.VersionFiles())
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+	fmt.Fprint(stdout, report.Diff())
+	if err := report.Err(); err != nil {
+		output.Logger().Fatal(err)
+	}
+}
+
+/*
+lintCommits checks the commit messages against the Conventional Commits specification; the violations are printed to stdout and the command fails if there are any
+  - by default the commits since the latest version tag are linted (all the commits if there is no version tag)
+  - if a message file is provided (e.g. by a commit-msg hook), only its message is linted
+*/
+func lintCommits(args internal.CliArgs) {
+	types := strings.Split(args.LintTypes, ",")
+	var violations []string
+	if args.LintMessageFile != "" {
+		contents, err := os.ReadFile(args.LintMessageFile)
+		if err != nil {
+			output.Logger().Fatal(err)
+		}
+		subject, body := conventionalCommit.SplitMessage(string(contents))
+		for _, err := range conventionalCommit.Lint(subject, body, types) {
+			violations = append(violations, fmt.Sprintf("%s: %v", args.LintMessageFile, err))
+		}
+	} else {
+		from := args.LintFrom
+		if from == "" {
+			if tag, err := (version.GitTagSource{Prefix: args.Prefix, Suffix: args.Suffix}).Read(); err == nil {
+				from = tag
+			}
+		}
+		commits, err := GitRepo.GetCommits(from, "HEAD", nil)
+		if err != nil {
 			output.Logger().Fatal(err)
 		}
+		for _, c := range commits {
+			for _, err := range conventionalCommit.Lint(c.Subject, c.Body, types) {
+				violations = append(violations, fmt.Sprintf("%s %s: %v", c.ShortHash, c.Subject, err))
+			}
+		}
+		output.Logger().WithFields(logrus.Fields{
+			"lintFrom":    from,
+			"lintCommits": len(commits),
+		}).Info("commits linted")
 	}
 
+	for _, v := range violations {
+		fmt.Fprintln(stdout, v)
+	}
+	if len(violations) > 0 {
+		output.Logger().WithField("violations", len(violations)).Fatal(ErrLint)
+	}
 }
 
-func setVersion(args internal.CliArgs) version.Version {
+// setVersion returns the current version and the incremented version
+func setVersion(args internal.CliArgs) (version.Version, version.Version) {
 	v := version.Version{
 		Prefix: args.Prefix,
 		Suffix: args.Suffix,
 	}
 
-	if args.CustomVersion == "" {
+	if args.VersionSources != "" {
+		sources, err := version.NewSources(strings.Split(args.VersionSources, ","), args.VersionSourceConfig())
+		if err != nil {
+			output.Logger().Fatal(err)
+		}
+		if err := v.SetVersionFromSources(sources); err != nil {
+			output.Logger().Fatal(err)
+		}
+	} else if args.CustomVersion == "" {
 		if err := v.SetVersionFromGit(); err != nil {
 			output.Logger().Fatal(err)
 		}
@@ -105,14 +324,211 @@ func setVersion(args internal.CliArgs) version.Version {
 		}
 	}
 
+	previous := v
 	if err := v.SetIncrementScope(args.VersionScopeAsString); err != nil {
 		output.Logger().Fatal(err)
 	}
-	return v
+	return previous, v
+}
+
+// componentRelease is a planned release of a component together with its incremented version
+type componentRelease struct {
+	component.Release
+	Previous version.Version
+	Version  version.Version
+	// Summary describes the release in the JSON release plan; it is set before the component is tagged
+	Summary release.Release
+}
+
+// releaseComponents releases the components of a monorepo that are provided as command line arguments
+func releaseComponents(args internal.CliArgs) {
+	if args.Push && !args.DryRun {
+		if err := versionControl.TrySetGitCredentialsBasicAuth(); err != nil {
+			output.Logger().Debug(err)
+		}
+	}
+
+	components := args.Components.WithPrefix(args.Prefix).WithDependencies(args.ComponentDependencies)
+	releases := planComponentReleases(args, components)
+	tagComponentReleases(args, releases)
 }
 
-// TagGit creates and pushes a git tag
-func TagGit(tag *versionControl.Tag, pushChanges bool) error {
+/*
+releaseGoModules releases the Go modules of the repository
+  - the modules are discovered from the root of the repository, which becomes the working directory: the tag prefixes, the paths of the git commands and the migrated files are all relative to it
+  - a module can be tagged with a major version greater than 1 only if its module path has the matching major version suffix
+  - if the migration is enabled, the module path of a module released with a MAJOR scope is migrated to the new major version before tagging
+*/
+func releaseGoModules(args internal.CliArgs) {
+	if args.Push && !args.DryRun {
+		if err := versionControl.TrySetGitCredentialsBasicAuth(); err != nil {
+			output.Logger().Debug(err)
+		}
+	}
+
+	root, err := GitRepo.GetRootDir()
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		output.Logger().Fatal(err)
+	}
+	modules, err := goModule.Discover(goModule.RootDir)
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+	modulesByPath := map[string]goModule.Module{}
+	for _, m := range modules {
+		modulesByPath[m.Path] = m
+	}
+
+	releases := planComponentReleases(args, goModule.Components(modules))
+	var migrations goModule.Migrations
+	for _, r := range releases {
+		m := modulesByPath[r.Component.Name]
+		err := m.ValidateMajor(r.Version.Major)
+		if err == nil {
+			continue
+		}
+		if !args.GoMajorMigrate || r.Scope.Id != version.MAJOR {
+			output.Logger().Fatal(err)
+		}
+
+		// the version of the requirements of the module, e.g. v2.0.0
+		newVersion := goModule.TagPrefix + strings.TrimPrefix(r.Version.String(), r.Version.Prefix)
+		migration, err := m.MigrateMajor(r.Version.Major, newVersion, modules, migrations)
+		if err != nil {
+			output.Logger().Fatal(err)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	if len(migrations) > 0 {
+		if err := MigrateGoModules(migrations, args.Push, args.DryRun); err != nil {
+			output.Logger().Fatal(err)
+		}
+		if !args.Push {
+			output.Logger().Warn(ErrNotPushMode)
+		}
+	}
+	tagComponentReleases(args, releases)
+}
+
+/*
+planComponentReleases creates the release plan for the components of a monorepo
+  - a component is released if it has relevant changes in its own path(s) or if one of its dependencies is released
+  - the components are released in topological order, so that a dependency is always tagged before its dependents
+  - with the JSON output, every release is described before any component is tagged: the previous tag and the commits analyzed are the ones of the planned release
+*/
+func planComponentReleases(args internal.CliArgs, components component.List) []componentRelease {
+	dependencyScope, err := component.ParseDependencyScope(args.ComponentDependencyScopeAsString)
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+
+	graph, err := component.NewGraph(components, dependencyScope)
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+
+	versions := map[string]version.Version{}
+	directScopes := map[string]version.Scope{}
+	for _, c := range components {
+		v := version.Version{
+			Prefix: c.Prefix,
+			Suffix: args.Suffix,
+		}
+		if c.InitialVersion != "" {
+			err = v.SetVersionFromGitOrDefault(c.InitialVersion)
+		} else {
+			err = v.SetVersionFromGit()
+		}
+		if err != nil {
+			output.Logger().Fatal(err)
+		}
+		s, err := v.ScopeForPaths(args.VersionScopeAsString, c.Paths)
+		if err != nil {
+			output.Logger().Fatal(err)
+		}
+		versions[c.Name] = v
+		directScopes[c.Name] = s
+	}
+
+	plan, err := graph.Plan(directScopes)
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+
+	var releases []componentRelease
+	for _, r := range plan {
+		previous := versions[r.Component.Name]
+		v := previous
+		if err := v.Increment(r.Scope); err != nil {
+			output.Logger().Fatal(err)
+		}
+		v.Scope = r.Scope
+		cr := componentRelease{Release: r, Previous: previous, Version: v}
+		if args.Output == internal.OutputJson {
+			cr.Summary = newRelease(previous, v, r.Component.Paths)
+			cr.Summary.Component = r.Component.Name
+			cr.Summary.TriggeredBy = r.TriggeredBy
+		}
+		releases = append(releases, cr)
+
+		output.Logger().WithFields(logrus.Fields{
+			"component":            r.Component.Name,
+			"componentVersion":     v.String(),
+			"componentScope":       r.Scope.String(),
+			"componentTriggeredBy": r.TriggeredBy,
+		}).Info("component release planned")
+	}
+	return releases
+}
+
+// tagComponentReleases tags the released components, generates their changelogs if enabled and prints the release plan to stdout: one tag per line, in the order in which the components are released
+func tagComponentReleases(args internal.CliArgs, releases []componentRelease) {
+	for _, r := range releases {
+		if args.ShouldTagGit {
+			tag := &versionControl.Tag{
+				Name: r.Version.String(),
+			}
+			if err := TagGit(tag, args.Push, args.DryRun); err != nil {
+				output.Logger().Fatal(err)
+			}
+		}
+	}
+	if args.ShouldTagGit && !args.Push && len(releases) > 0 {
+		output.Logger().Warn(ErrNotPushMode)
+	}
+	if args.Changelog {
+		for _, r := range releases {
+			if err := writeChangelog(newComponentChangelog(args, r), args.DryRun); err != nil {
+				output.Logger().Fatal(err)
+			}
+		}
+	}
+
+	if args.Output == internal.OutputJson {
+		printComponentPlan(args, releases)
+		return
+	}
+	for _, r := range releases {
+		fmt.Fprintln(stdout, r.Version.String())
+	}
+}
+
+// TagGit creates and pushes a git tag; in dry-run mode the git commands are only printed
+func TagGit(tag *versionControl.Tag, pushChanges, dryRun bool) error {
+	if dryRun {
+		if pushChanges {
+			printDryRun("git tag --annotate %q", tag.Name)
+			printDryRun("git push origin %q", tag.Name)
+			recordAction(release.ActionTag, tag.Name, true)
+		} else {
+			printDryRun("git tag %q skipped: use the `-push` flag", tag.Name)
+		}
+		return nil
+	}
 	if pushChanges {
 		if err := tag.Create(); err != nil {
 			return err
@@ -120,6 +536,7 @@ func TagGit(tag *versionControl.Tag, pushChanges bool) error {
 		if err := tag.Push(); err != nil {
 			return err
 		}
+		recordAction(release.ActionTag, tag.Name, true)
 	}
 	output.Logger().WithFields(logrus.Fields{
 		"tag":       tag.Name,
@@ -128,24 +545,44 @@ func TagGit(tag *versionControl.Tag, pushChanges bool) error {
 	return nil
 }
 
-// TagFile updates a substring in a file based on a pattern
-func TagFile(ver version.Version, filePath string, versionPattern string, pushChanges bool) error {
+/*
+TagFiles updates the version in all the files as a single transaction; each file is updated based on a pattern, a structured version path or a built-in preset
+  - all the files are validated and their new contents are calculated before any file is written
+  - if a file can't be written or added to the git index, the files already written are restored to their original contents
+  - the generated files (e.g. the Go version file) are written and committed together with the version files
+  - all the files are committed together and pushed once
+  - in dry-run mode, the unified diff of every file and the git commands are only printed
+*/
+func TagFiles(ver version.Version, files []version.File, generated version.Changes, pushChanges, dryRun bool) error {
 	const commitMsgVerBump = "chore(version): "
 
-	f := version.File{
-		Path:          filePath,
-		VersionFormat: versionPattern,
-		Version:       ver.String(),
-	}
-	newContents, err := f.ReplaceSubstring()
+	changes, err := version.PlanChanges(files, ver.String())
 	if err != nil {
 		return err
 	}
+	for _, c := range generated {
+		if c.Old == c.New {
+			output.Logger().WithField("filePath", c.Path).Info("the file already contains the version")
+			continue
+		}
+		changes = append(changes, c)
+	}
+	if len(changes) == 0 {
+		output.Logger().Info("no file needs to be updated")
+		return nil
+	}
+	for _, p := range changes.Paths() {
+		recordAction(release.ActionFile, p, pushChanges)
+	}
+	if dryRun {
+		printDryRunChanges(changes, commitMsgVerBump+ver.String(), true, pushChanges)
+		return nil
+	}
 
-	if err := f.Write(newContents); err != nil {
+	if err := changes.Apply(); err != nil {
 		return err
 	}
-	if err := GitRepo.Add(filePath); err != nil {
+	if err := stageChanges(changes); err != nil {
 		return err
 	}
 	if pushChanges {
@@ -157,8 +594,102 @@ func TagFile(ver version.Version, filePath string, versionPattern string, pushCh
 		}
 	}
 	output.Logger().WithFields(logrus.Fields{
-		"file":              f.Path,
+		"files":             changes.Paths(),
 		"fileChangesPushed": pushChanges,
-	}).Info("the file has been updated")
+	}).Info("the files have been updated")
 	return nil
 }
+
+// buildInfo returns the build information of the release: the version, and the hash and the date (in UTC) of the HEAD commit
+func buildInfo(v version.Version) (goModule.BuildInfo, error) {
+	hash, err := GitRepo.GetHash()
+	if err != nil {
+		return goModule.BuildInfo{}, err
+	}
+	date, err := GitRepo.GetCommitDate("HEAD")
+	if err != nil {
+		return goModule.BuildInfo{}, err
+	}
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		date = t.UTC().Format(time.RFC3339)
+	}
+	return goModule.BuildInfo{Version: v.String(), Commit: hash, Date: date}, nil
+}
+
+/*
+MigrateGoModules rewrites the module paths of the Go modules to their new major versions
+  - all the files are written as a single transaction: if a file can't be written, the files already written are restored
+  - with -push, the files are added, committed and pushed together; otherwise they are left unstaged in the working tree
+  - in dry-run mode, the unified diff of every file and the git commands are only printed
+*/
+func MigrateGoModules(migrations goModule.Migrations, pushChanges, dryRun bool) error {
+	const commitMsgMigration = "chore(version): migrate the Go module path(s) to the new major version: "
+
+	changes := migrations.Changes()
+	paths := migrations.NewPaths()
+	for _, p := range paths {
+		recordAction(release.ActionGoModuleMigrate, p, pushChanges)
+	}
+	if dryRun {
+		printDryRunChanges(changes, commitMsgMigration+strings.Join(paths, ", "), pushChanges, pushChanges)
+		return nil
+	}
+
+	if err := changes.Apply(); err != nil {
+		return err
+	}
+	if pushChanges {
+		if err := stageChanges(changes); err != nil {
+			return err
+		}
+		if err := GitRepo.Commit(commitMsgMigration + strings.Join(paths, ", ")); err != nil {
+			return err
+		}
+		if err := GitRepo.Push(""); err != nil {
+			return err
+		}
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"modules":           paths,
+		"files":             changes.Paths(),
+		"fileChangesPushed": pushChanges,
+	}).Info("the Go module paths have been migrated")
+	return nil
+}
+
+// stageChanges adds the changed files to the git index; if a file can't be added, all the files are restored to their original contents and the files already added are staged again, so that the index matches the restored files
+func stageChanges(changes version.Changes) error {
+	for i, p := range changes.Paths() {
+		err := GitRepo.Add(p)
+		if err == nil {
+			continue
+		}
+		err = changes.Rollback(err)
+		for _, staged := range changes.Paths()[:i] {
+			if addErr := GitRepo.Add(staged); addErr != nil {
+				output.Logger().WithField("filePath", staged).Warn(addErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// printDryRun prints an action that would be executed without the -dry-run flag
+func printDryRun(format string, a ...interface{}) {
+	fmt.Fprintf(stdout, "[dry-run] "+format+"\n", a...)
+}
+
+// printDryRunChanges prints the unified diff of the file changes and the git commands that would stage, commit and push them
+func printDryRunChanges(changes version.Changes, commitMsg string, stage, pushChanges bool) {
+	fmt.Fprint(stdout, changes.Diff())
+	if stage {
+		for _, p := range changes.Paths() {
+			printDryRun("git add %s", p)
+		}
+	}
+	if pushChanges {
+		printDryRun("git commit -m %q", commitMsg)
+		printDryRun("git push origin --all")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"semtag/internal"
+	"semtag/pkg/component"
+	"semtag/pkg/release"
+	"semtag/pkg/version"
+	"semtag/pkg/versionControl"
+)
+
+// gitRepositoryStub is a git repository mock that records the staged files and fails to stage a file; its history has a latest tag and the commits since each tag
+type gitRepositoryStub struct {
+	versionControl.GitRepositoryMock
+	failAdd string
+	added   []string
+
+	latestTag string
+	commits   map[string][]versionControl.CommitInfo
+}
+
+func (g *gitRepositoryStub) GetLatestTag(prefix, baseRegex, suffix string) (string, error) {
+	if g.latestTag == "" {
+		return "", errors.New("no tag found")
+	}
+	return g.latestTag, nil
+}
+
+func (g *gitRepositoryStub) GetCommits(from, to string, paths []string) ([]versionControl.CommitInfo, error) {
+	return g.commits[from], nil
+}
+
+func (g *gitRepositoryStub) GetCommitLogsSince(ref string, paths []string) (string, error) {
+	var logs []string
+	for _, c := range g.commits[ref] {
+		logs = append(logs, c.Subject)
+	}
+	return strings.Join(logs, "\n"), nil
+}
+
+func (g *gitRepositoryStub) Add(file string) error {
+	if file == g.failAdd {
+		return errors.New("unable to add the file")
+	}
+	g.added = append(g.added, file)
+	return nil
+}
+
+func Test_TagFilesRollback(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "VERSION"), filepath.Join(dir, "package.json")
+	contents := map[string]string{first: "1.2.3\n", second: `{"version": "1.2.3"}`}
+	for p, c := range contents {
+		if err := ioutil.WriteFile(p, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := &gitRepositoryStub{failAdd: second}
+	GitRepo = repo
+	ver := version.Version{Major: 2}
+
+	// act
+	err := TagFiles(ver, []version.File{{Path: first}, {Path: second}}, nil, false, false)
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), version.ErrApplyChanges.Error()) {
+		t.Errorf("got %v want %q", err, version.ErrApplyChanges)
+	}
+	for p, want := range contents {
+		dat, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(dat) != want {
+			t.Errorf("got %q want %q: the file must be restored", dat, want)
+		}
+	}
+	// the first file is staged again with its original contents
+	if len(repo.added) != 2 || repo.added[0] != first || repo.added[1] != first {
+		t.Errorf("got %v want %s staged twice", repo.added, first)
+	}
+}
+
+func Test_ComponentPlanAfterTagging(t *testing.T) {
+	// arrange
+	repo := &gitRepositoryStub{
+		latestTag: "api/v1.0.0",
+		commits: map[string][]versionControl.CommitInfo{
+			"api/v1.0.0": {{Hash: "a1b2c3d4", ShortHash: "a1b2c3d", Subject: "feat: add the export"}},
+		},
+	}
+	GitRepo = repo
+	version.GitRepo = repo
+	args := internal.CliArgs{VersionScopeAsString: "auto", Output: internal.OutputJson, ComponentDependencyScopeAsString: "patch"}
+	components := component.List{{Name: "api", Paths: []string{"api"}, Prefix: "api/v"}}
+	out, err := ioutil.TempFile(t.TempDir(), "plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	os.Stdout = out
+
+	// act
+	releases := planComponentReleases(args, components)
+	// the pushed tag becomes the latest tag, without any commit since
+	repo.latestTag = "api/v1.1.0"
+	repo.commits = nil
+	tagComponentReleases(args, releases)
+
+	// assert
+	dat, err := ioutil.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var plan release.Plan
+	if err := json.Unmarshal(dat, &plan); err != nil {
+		t.Fatalf("got %q: %v", dat, err)
+	}
+	if len(plan.Components) != 1 {
+		t.Fatalf("got %d components want 1", len(plan.Components))
+	}
+	got := plan.Components[0]
+	if got.PreviousTag != "api/v1.0.0" || got.Tag != "api/v1.1.0" || !got.ReleaseNeeded {
+		t.Errorf("got previousTag=%q, tag=%q, releaseNeeded=%t want api/v1.0.0, api/v1.1.0 and true", got.PreviousTag, got.Tag, got.ReleaseNeeded)
+	}
+	if len(got.CommitsAnalyzed) != 1 || got.CommitsAnalyzed[0].Hash != "a1b2c3d4" {
+		t.Errorf("got commits analyzed %+v want the commit since the previous tag", got.CommitsAnalyzed)
+	}
+}
@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/conventionalCommit"
+	"semtag/pkg/output"
+	"semtag/pkg/versionControl"
+)
+
+// Changelog contains the releases of the repository, from the newest to the oldest
+type Changelog struct {
+	// Unreleased contains the commits since the latest release; its tag is empty
+	Unreleased Release
+	Releases   []Release
+}
+
+// Commit is a git commit and its commit message parsed as a Conventional Commit
+type Commit struct {
+	versionControl.CommitInfo
+	Message conventionalCommit.Message
+	// CoAuthors are parsed from the Co-authored-by trailers and resolved with the mailmap
+	CoAuthors []Identity
+}
+
+// Release is a version tag and the commits included in it
+type Release struct {
+	Tag string
+	// Previous is the tag of the previous release; it is empty for the oldest release
+	Previous string
+	Date     time.Time
+	// Commits since the previous version tag, from the oldest to the newest
+	Commits []Commit
+	// Contributors are the authors and the co-authors of the commits, in the order of their first commit
+	Contributors []Contributor
+}
+
+/*
+Collect the releases of the repository from the git tags that match the regex
+  - a release contains the commits between the previous matching tag and its own tag; the oldest release contains all the commits up to its tag (the former shell-based changelog had no release for the oldest tag)
+  - the commits since the latest release are collected as unreleased
+  - the merge commits are skipped
+  - if paths are provided, only the commits that changed any of them are collected (e.g. the paths of a component of a monorepo)
+  - the contributors of a release are first-time contributors if they have no commit in the previous releases
+*/
+func Collect(regex string, paths []string) (Changelog, error) {
+	tags, err := GitRepo.GetTags(regex)
+	if err != nil {
+		return Changelog{}, err
+	}
+
+	var cl Changelog
+	for i, tag := range tags {
+		var previous string
+		if i+1 < len(tags) {
+			previous = tags[i+1].Name
+		}
+		commits, err := collectCommits(previous, tag.Name, paths)
+		if err != nil {
+			return Changelog{}, err
+		}
+		cl.Releases = append(cl.Releases, Release{Tag: tag.Name, Previous: previous, Date: tag.Date, Commits: commits})
+	}
+
+	var latest string
+	if len(tags) > 0 {
+		latest = tags[0].Name
+	}
+	unreleased, err := collectCommits(latest, "HEAD", paths)
+	if err != nil {
+		return Changelog{}, err
+	}
+	cl.Unreleased = Release{Previous: latest, Commits: unreleased}
+
+	known := map[string]bool{}
+	for i := len(cl.Releases) - 1; i >= 0; i-- {
+		cl.Releases[i].Contributors = contributors(cl.Releases[i].Commits, known)
+	}
+	cl.Unreleased.Contributors = contributors(cl.Unreleased.Commits, known)
+
+	output.Logger().WithFields(logrus.Fields{
+		"changelogGitTagRegex": regex,
+		"changelogPaths":       paths,
+		"changelogReleases":    len(cl.Releases),
+	}).Debug("collected the releases for the changelog")
+	return cl, nil
+}
+
+// collectCommits returns the commits between two refs that changed the paths (all the commits if there is no path), with their parsed commit messages and their co-authors
+func collectCommits(from, to string, paths []string) ([]Commit, error) {
+	infos, err := GitRepo.GetCommits(from, to, paths)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, info := range infos {
+		commits = append(commits, Commit{CommitInfo: info, Message: conventionalCommit.Parse(info.Subject, info.Body)})
+	}
+	identities, err := coAuthors(commits)
+	if err != nil {
+		return nil, err
+	}
+	for i := range commits {
+		commits[i].CoAuthors = identities[i]
+	}
+	return commits, nil
+}
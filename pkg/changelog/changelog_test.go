@@ -0,0 +1,298 @@
+package changelog
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"semtag/pkg/versionControl"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// gitRepositoryHistoryMock returns predefined tags and commits
+type gitRepositoryHistoryMock struct {
+	versionControl.GitRepositoryMock
+	tags    []versionControl.TagInfo
+	commits map[string][]versionControl.CommitInfo
+}
+
+func (g *gitRepositoryHistoryMock) GetTags(regex string) ([]versionControl.TagInfo, error) {
+	return g.tags, nil
+}
+
+func (g *gitRepositoryHistoryMock) GetCommits(from, to string, paths []string) ([]versionControl.CommitInfo, error) {
+	return g.commits[from+".."+to], nil
+}
+
+func (g *gitRepositoryHistoryMock) GetCommitDate(ref string) (string, error) {
+	return "2021-06-05T10:00:00+02:00", nil
+}
+
+// testHistory returns a repository with two releases and an unreleased commit; the second release has new contributors
+func testHistory() *gitRepositoryHistoryMock {
+	date := func(day int) time.Time {
+		return time.Date(2021, time.June, day, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	}
+	commit := func(hash, subject, body string, day int) versionControl.CommitInfo {
+		return versionControl.CommitInfo{
+			Hash:        hash + strings.Repeat("0", 40-len(hash)),
+			ShortHash:   hash,
+			AuthorName:  "Ann Dev",
+			AuthorEmail: "ann@example.com",
+			Date:        date(day),
+			Subject:     subject,
+			Body:        body,
+		}
+	}
+	contributed := func(c versionControl.CommitInfo, name, email string) versionControl.CommitInfo {
+		c.AuthorName, c.AuthorEmail = name, email
+		return c
+	}
+	return &gitRepositoryHistoryMock{
+		tags: []versionControl.TagInfo{
+			{Name: "v1.1.0", Hash: "c3", Date: date(3)},
+			{Name: "v1.0.0", Hash: "a1", Date: date(1)},
+		},
+		commits: map[string][]versionControl.CommitInfo{
+			"..v1.0.0": {
+				commit("a1b2c3d", "feat: initial release", "", 1),
+			},
+			"v1.0.0..v1.1.0": {
+				commit("b2c3d4e", "fix(api): handle 100% of the requests", "Closes #12", 2),
+				commit("c3d4e5f", "feat: add the export", "The export is a CSV file.\n\nBREAKING CHANGE: the export format changed\nfrom JSON to CSV", 3),
+				commit("d4e5f6a", "perf(db)!: drop the cache", "", 3),
+				contributed(commit("e5f6a7b", "chore(deps): bump yaml (#7)", "Co-authored-by: Cy Ops <cy@example.com>\nCo-authored-by: Ann Dev <ANN@example.com>", 3), "Bo Bot", "bo@example.com"),
+				commit("f6a7b8c", "Update README.md", "", 3),
+			},
+			"v1.1.0..HEAD": {
+				commit("a7b8c9d", "feat(api): add the <import> & export", "", 4),
+			},
+		},
+	}
+}
+
+func Test_Render(t *testing.T) {
+	// arrange
+	GitRepo = testHistory()
+	confluence, err := LoadTemplate(filepath.Join("testdata", "confluence.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := Links{
+		CommitUrl:  "https://example.com/repo/commit",
+		TagUrl:     "https://example.com/repo/tags/",
+		CompareUrl: "https://example.com/repo/compare",
+		IssueUrl:   "https://example.com/repo/issues/{id}",
+		References: References{Footers: true},
+	}
+	tables := []struct {
+		golden   string
+		renderer Renderer
+	}{
+		{"markdown.golden", Markdown{Links: links}},
+		{"markdownNoLinks.golden", Markdown{}},
+		{"markdownSections.golden", Markdown{Sections: Sections{
+			{Title: "New", Types: []string{"feat", "perf"}},
+			{Title: "Maintenance", Types: []string{"chore"}},
+			{Title: "Breaking", Types: []string{SectionTypeBreaking}},
+		}}},
+		{"confluence.golden", Template{Template: confluence, Links: links}},
+		{"keepAChangelog.golden", Formats[FormatKeepAChangelog].Renderer(links, nil)},
+		{"keepAChangelogNoLinks.golden", Formats[FormatKeepAChangelog].Renderer(Links{}, nil)},
+		{"changelog.json.golden", Formats[FormatJson].Renderer(links, nil)},
+		{"changelog.html.golden", Formats[FormatHtml].Renderer(links, nil)},
+		{"changelog.adoc.golden", Formats[FormatAsciidoc].Renderer(links, nil)},
+		{"debian.golden", Formats[FormatDebian].PackageRenderer(testPackage(), nil)},
+		{"rpm.golden", Formats[FormatRpm].PackageRenderer(testPackage(), nil)},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.golden, func(t *testing.T) {
+			cl, err := Collect(DefaultRegexFormat, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			err = tb.renderer.Render(&b, cl)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tb.golden, b.String())
+		})
+	}
+}
+
+// assertGolden compares the output with the golden file in testdata; the golden file is overwritten if the -update flag is set
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// gitRepositoryRemoteMock returns a predefined remote URL
+type gitRepositoryRemoteMock struct {
+	versionControl.GitRepositoryMock
+	remoteUrl string
+}
+
+func (g *gitRepositoryRemoteMock) GetRemoteUrl(name string) (string, error) {
+	return g.remoteUrl, nil
+}
+
+func Test_LogLinks(t *testing.T) {
+	// arrange
+	tables := []struct {
+		remoteUrl string
+		hostType  string
+		hostUrl   string
+
+		wantCommit  string
+		wantTag     string
+		wantCompare string
+	}{
+		{"git@github.com:org/repo.git", "", "",
+			"https://github.com/org/repo/commit/a1b2c3d", "https://github.com/org/repo/releases/tag/v1.1.0", "https://github.com/org/repo/compare/v1.0.0...v1.1.0"},
+		{"https://bitbucket.org/org/repo.git", "", "",
+			"https://bitbucket.org/org/repo/commits/a1b2c3d", "https://bitbucket.org/org/repo/src/v1.1.0", "https://bitbucket.org/org/repo/branches/compare/v1.1.0%0Dv1.0.0"},
+		{"git@git.example.com:org/repo.git", "gitlab", "",
+			"https://git.example.com/org/repo/-/commit/a1b2c3d", "https://git.example.com/org/repo/-/tags/v1.1.0", "https://git.example.com/org/repo/-/compare/v1.0.0...v1.1.0"},
+		{"git@mirror:repo.git", "gitea", "https://git.example.com/org/repo",
+			"https://git.example.com/org/repo/commit/a1b2c3d", "https://git.example.com/org/repo/src/tag/v1.1.0", "https://git.example.com/org/repo/compare/v1.0.0...v1.1.0"},
+		{"git@git.example.com:org/repo.git", "", "", "", "", ""},
+		{"", "", "", "", "", ""},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("remoteUrl=%q, hostType=%q", tb.remoteUrl, tb.hostType), func(t *testing.T) {
+			GitRepo = &gitRepositoryRemoteMock{remoteUrl: tb.remoteUrl}
+			l := Log{HostType: tb.hostType, HostUrl: tb.hostUrl}
+			links := l.links()
+
+			// assert
+			if got := links.commit("a1b2c3d"); got != tb.wantCommit {
+				t.Errorf("got commit %q want %q", got, tb.wantCommit)
+			}
+			if got := links.tag("v1.1.0"); got != tb.wantTag {
+				t.Errorf("got tag %q want %q", got, tb.wantTag)
+			}
+			if got := links.compare("v1.0.0", "v1.1.0"); got != tb.wantCompare {
+				t.Errorf("got compare %q want %q", got, tb.wantCompare)
+			}
+		})
+	}
+}
+
+func Test_LogRenderRelease(t *testing.T) {
+	// arrange
+	GitRepo = testHistory()
+	tables := []struct {
+		format  string
+		version string
+
+		wantPrefix string
+		wantCommit string
+	}{
+		{FormatMarkdown, "v1.1.0", "## v1.1.0\n2021-06-03 08:00:00 +0000\n\n### Breaking Changes\n", "drop the cache"},
+		{FormatMarkdown, "v1.2.0", "## v1.2.0\n2021-06-05 08:00:00 +0000\n\n### Features\n", "add the <import> & export"},
+		{FormatKeepAChangelog, "v1.2.0", "## [v1.2.0] - 2021-06-05\n\n### Added\n", "add the <import> & export"},
+		{FormatJson, "v1.2.0", "{\n  \"schemaVersion\": 1,\n  \"tag\": \"v1.2.0\",\n  \"previousTag\": \"v1.1.0\",\n", "add the <import> & export"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("format=%q, version=%q", tb.format, tb.version), func(t *testing.T) {
+			l := Log{Format: tb.format, Version: tb.version}
+			got, err := l.RenderRelease()
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(got, tb.wantPrefix) {
+				t.Errorf("got:\n%s\nwant prefix:\n%s", got, tb.wantPrefix)
+			}
+			if !strings.Contains(got, tb.wantCommit) || strings.Contains(got, "initial release") {
+				t.Errorf("got:\n%s\nwant only the commits of the release %s", got, tb.version)
+			}
+		})
+	}
+}
+
+// gitRepositoryPathsMock records the paths of the retrieved commits
+type gitRepositoryPathsMock struct {
+	*gitRepositoryHistoryMock
+	paths [][]string
+}
+
+func (g *gitRepositoryPathsMock) GetCommits(from, to string, paths []string) ([]versionControl.CommitInfo, error) {
+	g.paths = append(g.paths, paths)
+	return g.gitRepositoryHistoryMock.GetCommits(from, to, paths)
+}
+
+func Test_LogPaths(t *testing.T) {
+	// arrange
+	tables := []struct {
+		release bool
+		format  string
+		paths   []string
+		dir     string
+
+		wantFileName string
+	}{
+		{false, FormatMarkdown, nil, "", "CHANGELOG.md"},
+		{false, FormatMarkdown, []string{"services/api", "proto/api"}, "services/api", "services/api/CHANGELOG.md"},
+		{true, FormatKeepAChangelog, []string{"lib/shared"}, "lib/shared", "lib/shared/CHANGELOG.md"},
+		{false, FormatJson, []string{"lib/shared"}, "lib/shared", "lib/shared/CHANGELOG.json"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("paths=%q", tb.paths), func(t *testing.T) {
+			repo := &gitRepositoryPathsMock{gitRepositoryHistoryMock: testHistory()}
+			GitRepo = repo
+			l := Log{Format: tb.format, Paths: tb.paths, Dir: tb.dir, Version: "v1.2.0"}
+			if tb.release {
+				_, err := l.RenderRelease()
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if _, err := l.Render(); err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			if got := l.FileName(); got != tb.wantFileName {
+				t.Errorf("got file name %q want %q", got, tb.wantFileName)
+			}
+			if len(repo.paths) == 0 {
+				t.Fatal("got no commits retrieved")
+			}
+			for _, got := range repo.paths {
+				if !reflect.DeepEqual(got, tb.paths) {
+					t.Errorf("got paths %q want %q", got, tb.paths)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// coAuthorFooterToken is the trailer of the co-authors of a commit (e.g. Co-authored-by: Jane Doe <jane@example.com>)
+	coAuthorFooterToken = "co-authored-by"
+)
+
+var (
+	// contactRegex matches a contact: Name <email>
+	contactRegex = regexp.MustCompile(`^(.*?)\s*<([^<>]*)>$`)
+)
+
+// Identity is the name and the email of an author or a co-author of a commit
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// key identifies a person: the email if it is set, otherwise the name (case insensitive)
+func (i Identity) key() string {
+	if i.Email != "" {
+		return strings.ToLower(i.Email)
+	}
+	return strings.ToLower(i.Name)
+}
+
+// contact formats the identity as a contact: Name <email>, or <email> if there is no name
+func (i Identity) contact() string {
+	if i.Name == "" {
+		return fmt.Sprintf("<%s>", i.Email)
+	}
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// Contributor is an author or a co-author of the commits of a release
+type Contributor struct {
+	Identity
+	// FirstTime is true if the contributor has no commit in the history of the repository before the release
+	FirstTime bool
+}
+
+// parseContact parses a contact with the format: Name <email>; the email is empty if the contact is only a name
+func parseContact(contact string) Identity {
+	contact = strings.TrimSpace(contact)
+	if match := contactRegex.FindStringSubmatch(contact); match != nil {
+		return Identity{Name: strings.TrimSpace(match[1]), Email: strings.TrimSpace(match[2])}
+	}
+	return Identity{Name: contact}
+}
+
+// coAuthors returns the co-authors of the commits from their Co-authored-by trailers; the identities with an email are resolved with the mailmap of the repository
+func coAuthors(commits []Commit) ([][]Identity, error) {
+	var contacts []string
+	identities := make([][]Identity, len(commits))
+	for i, c := range commits {
+		for _, f := range c.Message.Footers {
+			if strings.ToLower(f.Token) != coAuthorFooterToken {
+				continue
+			}
+			id := parseContact(f.Value)
+			if id.Name == "" && id.Email == "" {
+				continue
+			}
+			identities[i] = append(identities[i], id)
+			if id.Email != "" {
+				contacts = append(contacts, id.contact())
+			}
+		}
+	}
+
+	resolved, err := GitRepo.CheckMailmap(contacts)
+	if err != nil {
+		return nil, err
+	}
+	canonical := map[string]Identity{}
+	for i, contact := range contacts {
+		if i < len(resolved) {
+			canonical[contact] = parseContact(resolved[i])
+		}
+	}
+	for i, c := range commits {
+		// the co-authors are deduplicated, and the author isn't a co-author of its own commit
+		seen := map[string]bool{Identity{Name: c.AuthorName, Email: c.AuthorEmail}.key(): true}
+		var resolvedIds []Identity
+		for _, id := range identities[i] {
+			if r, ok := canonical[id.contact()]; ok && id.Email != "" {
+				id = r
+			}
+			if !seen[id.key()] {
+				seen[id.key()] = true
+				resolvedIds = append(resolvedIds, id)
+			}
+		}
+		identities[i] = resolvedIds
+	}
+	return identities, nil
+}
+
+/*
+contributors returns the authors and the co-authors of the commits, in the order of their first commit
+  - a person is identified by the email (or by the name if there is no email), after the mailmap resolution
+  - a contributor that isn't known yet is a first-time contributor; the contributors are then added to the known people
+*/
+func contributors(commits []Commit, known map[string]bool) []Contributor {
+	var out []Contributor
+	seen := map[string]bool{}
+	add := func(id Identity) {
+		if seen[id.key()] {
+			return
+		}
+		seen[id.key()] = true
+		out = append(out, Contributor{Identity: id, FirstTime: !known[id.key()]})
+	}
+	for _, c := range commits {
+		add(Identity{Name: c.AuthorName, Email: c.AuthorEmail})
+		for _, co := range c.CoAuthors {
+			add(co)
+		}
+	}
+	for key := range seen {
+		known[key] = true
+	}
+	return out
+}
+
+// knownContributors returns the people that contributed to the commits reachable from a ref that changed the paths (none if the ref is empty)
+func knownContributors(ref string, paths []string) (map[string]bool, error) {
+	known := map[string]bool{}
+	if ref == "" {
+		return known, nil
+	}
+	commits, err := collectCommits("", ref, paths)
+	if err != nil {
+		return nil, err
+	}
+	contributors(commits, known)
+	return known, nil
+}
+
+// WithoutEmails removes the email addresses of the authors, the co-authors and the contributors, so that they aren't published in the changelog
+func (cl Changelog) WithoutEmails() Changelog {
+	out := Changelog{Unreleased: cl.Unreleased.WithoutEmails()}
+	for _, r := range cl.Releases {
+		out.Releases = append(out.Releases, r.WithoutEmails())
+	}
+	return out
+}
+
+// WithoutEmails removes the email addresses of the authors, the co-authors and the contributors of the release
+func (r Release) WithoutEmails() Release {
+	out := r
+	out.Commits = nil
+	for _, c := range r.Commits {
+		c.AuthorEmail = ""
+		var co []Identity
+		for _, id := range c.CoAuthors {
+			co = append(co, Identity{Name: id.Name})
+		}
+		c.CoAuthors = co
+		out.Commits = append(out.Commits, c)
+	}
+	out.Contributors = nil
+	for _, c := range r.Contributors {
+		c.Email = ""
+		out.Contributors = append(out.Contributors, c)
+	}
+	return out
+}
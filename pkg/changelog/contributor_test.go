@@ -0,0 +1,147 @@
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gitRepositoryMailmapMock resolves the contacts with a predefined mailmap
+type gitRepositoryMailmapMock struct {
+	*gitRepositoryHistoryMock
+	mailmap map[string]string
+}
+
+func (g *gitRepositoryMailmapMock) CheckMailmap(contacts []string) ([]string, error) {
+	var out []string
+	for _, c := range contacts {
+		if canonical, ok := g.mailmap[c]; ok {
+			c = canonical
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+func Test_ParseContact(t *testing.T) {
+	// arrange
+	tables := []struct {
+		contact string
+
+		want Identity
+	}{
+		{"Jane Doe <jane@example.com>", Identity{Name: "Jane Doe", Email: "jane@example.com"}},
+		{"  Jane Doe   <jane@example.com> ", Identity{Name: "Jane Doe", Email: "jane@example.com"}},
+		{"<jane@example.com>", Identity{Email: "jane@example.com"}},
+		{"Jane Doe", Identity{Name: "Jane Doe"}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("contact=%q", tb.contact), func(t *testing.T) {
+			got := parseContact(tb.contact)
+
+			// assert
+			if got != tb.want {
+				t.Errorf("got %+v want %+v", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_IdentityContact(t *testing.T) {
+	// arrange
+	tables := []struct {
+		identity Identity
+
+		want string
+	}{
+		{Identity{Name: "Jane Doe", Email: "jane@example.com"}, "Jane Doe <jane@example.com>"},
+		{Identity{Email: "jane@example.com"}, "<jane@example.com>"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("identity=%+v", tb.identity), func(t *testing.T) {
+			got := tb.identity.contact()
+
+			// assert
+			if got != tb.want {
+				t.Errorf("got %q want %q", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_CollectContributors(t *testing.T) {
+	// arrange
+	GitRepo = &gitRepositoryMailmapMock{
+		gitRepositoryHistoryMock: testHistory(),
+		mailmap:                  map[string]string{"Cy Ops <cy@example.com>": "Cyril Ops <cyril@example.com>"},
+	}
+	want := []Contributor{
+		{Identity: Identity{Name: "Ann Dev", Email: "ann@example.com"}},
+		{Identity: Identity{Name: "Bo Bot", Email: "bo@example.com"}, FirstTime: true},
+		{Identity: Identity{Name: "Cyril Ops", Email: "cyril@example.com"}, FirstTime: true},
+	}
+
+	// act
+	cl, err := Collect(DefaultRegexFormat, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := CollectRelease(DefaultRegexFormat, "v1.1.0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if got := cl.Releases[0].Contributors; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+	if got := r.Contributors; !reflect.DeepEqual(got, want) {
+		t.Errorf("got release contributors %+v want %+v", got, want)
+	}
+	if got := cl.Unreleased.Contributors; len(got) != 1 || got[0].FirstTime {
+		t.Errorf("got unreleased contributors %+v want Ann Dev without first contribution", got)
+	}
+}
+
+func Test_LogEmails(t *testing.T) {
+	// arrange
+	GitRepo = testHistory()
+	tables := []struct {
+		emails bool
+
+		want bool
+	}{
+		{false, false},
+		{true, true},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("emails=%v", tb.emails), func(t *testing.T) {
+			l := Log{Emails: tb.emails}
+			got, err := l.Render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			release, err := (&Log{Emails: tb.emails, Version: "v1.1.0"}).RenderRelease()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			for _, out := range []string{got, release} {
+				if strings.Contains(out, "@example.com") != tb.want {
+					t.Errorf("got:\n%s\nwant emails %v", out, tb.want)
+				}
+				if !strings.Contains(out, "Cy Ops") {
+					t.Errorf("got:\n%s\nwant the co-author Cy Ops", out)
+				}
+			}
+		})
+	}
+}
@@ -1,12 +1,10 @@
 package changelog
 
 import (
-	"fmt"
-
 	"github.com/sirupsen/logrus"
 
 	"semtag/pkg/output"
-	"semtag/pkg/terminal"
+	"semtag/pkg/version"
 )
 
 const (
@@ -36,49 +34,14 @@ func (f *file) String(prefix, suffix string) string {
 	return out
 }
 
-// Generate the contents of the changelog and write them to a file
-func (f *file) Generate(fileName, regex, commitUrl, tagUrl string) error {
-	script := fmt.Sprintf(`
-# write the changelog to the specified file name
-cat <<"EOF" | bash > %q
-#!/usr/bin/env bash
-set -euo pipefail
-
-previous_tag=0
-
-# for each of the tags that match the provided regex create a changelog entry
-for current_tag in $(git tag --sort=-v:refname | grep -E %s ); do
-
-  if [ "$previous_tag" != 0 ]; then
-	# get the commit datetime
-    tag_date=$(TZ=UTC git log -1 --pretty=format:'%%ad' --date=iso-local ${previous_tag})
-
-	# set the changelog entry header
-	# use the tag url to generate the hyperlinks for tags
-    printf "## [${previous_tag}](%s/${previous_tag})\n${tag_date}\n\n"
-
-	# set the changelog entry body
-	# use the commit url to generate the hyperlinks for commits
-	# ignore merge commits
-    TZ=UTC git log ${current_tag}...${previous_tag} --pretty=format:'*  %%s by [%%aN](mailto:%%aE) ([%%h](%s/%%H))' --reverse | grep Merge \
-		|| TZ=UTC git log ${current_tag}...${previous_tag} --pretty=format:'*  %%s by [%%aN](mailto:%%aE) ([%%h](%s/%%H))' --reverse
-
-    printf "\n\n"
-  fi
-
-  previous_tag=${current_tag}
-done
-EOF`,
-		fileName, regex, tagUrl, commitUrl, commitUrl)
-	_, err := terminal.Shell(script)
-	if err != nil {
+// Write the contents of the changelog to the file
+func (f *file) Write(contents string) error {
+	vf := version.File{Path: f.name}
+	if err := vf.Write(contents); err != nil {
 		return err
 	}
-
 	output.Logger().WithFields(logrus.Fields{
-		"changelogFile":        fileName,
-		"changelogGitTagRegex": regex,
-		"changelogBaseUrls":    []string{tagUrl, commitUrl},
+		"changelogFile": f.name,
 	}).Info("changelog generated")
 	return nil
 }
@@ -0,0 +1,140 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	FormatMarkdown       = "markdown"
+	FormatKeepAChangelog = "keep-a-changelog"
+	FormatJson           = "json"
+	FormatHtml           = "html"
+	FormatAsciidoc       = "asciidoc"
+	FormatDebian         = "debian"
+	FormatRpm            = "rpm"
+
+	DefaultFormat = FormatMarkdown
+)
+
+var (
+	ErrUnknownFormat     = errors.New("unknown changelog format")
+	ErrIncrementalFormat = errors.New("the changelog format doesn't support the incremental mode")
+	ErrReleaseFormat     = errors.New("the changelog format can't render a single release")
+
+	// KeepAChangelogSections are the sections of the Keep a Changelog format (https://keepachangelog.com); the commits of the other types are not listed
+	KeepAChangelogSections = Sections{
+		{Title: "Added", Types: []string{"feat"}},
+		{Title: "Changed", Types: []string{SectionTypeBreaking, "perf", "refactor"}},
+		{Title: "Fixed", Types: []string{"fix"}},
+		{Title: "Security", Types: []string{"security"}},
+	}
+
+	// Formats are the built-in changelog formats by name
+	Formats = map[string]Format{
+		FormatMarkdown: {
+			Name:        FormatMarkdown,
+			FileName:    DefaultChangelogFile,
+			Incremental: true,
+			template:    "markdown.tmpl",
+		},
+		FormatKeepAChangelog: {
+			Name:        FormatKeepAChangelog,
+			FileName:    DefaultChangelogFile,
+			Sections:    KeepAChangelogSections,
+			Incremental: true,
+			template:    "keepAChangelog.tmpl",
+		},
+		FormatJson: {
+			Name:     FormatJson,
+			FileName: "CHANGELOG.json",
+		},
+		FormatHtml: {
+			Name:        FormatHtml,
+			FileName:    "CHANGELOG.html",
+			Incremental: true,
+			template:    "html.tmpl",
+			html:        true,
+		},
+		FormatAsciidoc: {
+			Name:     FormatAsciidoc,
+			FileName: "CHANGELOG.adoc",
+			template: "asciidoc.tmpl",
+		},
+		FormatDebian: {
+			Name:        FormatDebian,
+			FileName:    "debian/changelog",
+			Incremental: true,
+			Package:     true,
+		},
+		FormatRpm: {
+			Name:        FormatRpm,
+			FileName:    "CHANGELOG.spec",
+			Incremental: true,
+			Package:     true,
+		},
+	}
+)
+
+// Format is a built-in changelog format
+type Format struct {
+	Name string
+	// FileName is the default name of the changelog file
+	FileName string
+	// Sections are the default sections of a release; the default sections of the package are used if none is set
+	Sections Sections
+	// Incremental is true if a release can be inserted in an existing changelog: below the insert marker, or with the syntax of the format (see Prepender)
+	Incremental bool
+	// Package is true for the changelogs of the Debian and RPM packages; they are rendered with the package metadata (see PackageRenderer)
+	Package bool
+
+	// template is the name of the built-in template; the JSON and the package formats have no template
+	template string
+	// html is true if the template is parsed with html/template
+	html bool
+}
+
+// FindFormat returns the built-in format with the name
+func FindFormat(name string) (Format, error) {
+	f, ok := Formats[name]
+	if !ok {
+		return Format{}, fmt.Errorf("%v: format=%q, formats=%q", ErrUnknownFormat, name, FormatNames())
+	}
+	return f, nil
+}
+
+// FormatNames returns the sorted names of the built-in formats
+func FormatNames() []string {
+	var names []string
+	for name := range Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Renderer returns the renderer of the format; the sections of the format are used if no section is provided
+func (f Format) Renderer(links Links, sections Sections) Renderer {
+	if len(sections) == 0 {
+		sections = f.Sections
+	}
+	if f.Package {
+		return f.PackageRenderer(Package{}, sections)
+	}
+	if f.template == "" {
+		return Json{Links: links, Sections: sections}
+	}
+	return Template{Template: builtinTemplate(f.template, f.html), Links: links, Sections: sections}
+}
+
+// PackageRenderer returns the renderer of a package format with the package metadata; the sections of the format are used if no section is provided
+func (f Format) PackageRenderer(p Package, sections Sections) Renderer {
+	if len(sections) == 0 {
+		sections = f.Sections
+	}
+	if f.Name == FormatRpm {
+		return Rpm{Package: p, Sections: sections}
+	}
+	return Debian{Package: p, Sections: sections}
+}
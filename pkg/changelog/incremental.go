@@ -0,0 +1,127 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/output"
+)
+
+const (
+	// InsertMarker is the line of the changelog below which the new releases are inserted
+	InsertMarker = "<!-- semtag:insert -->"
+
+	// releaseMarkerFormat is the hidden line that precedes an inserted release; it is used to detect the releases already in the changelog
+	releaseMarkerFormat = "<!-- semtag:release %s -->"
+
+	newChangelogHeader = "# Changelog\n\n"
+)
+
+var (
+	ErrInsertMarkerNotFound = errors.New("the changelog doesn't contain the insert marker")
+)
+
+/*
+CollectRelease collects a single release of the repository
+  - if the tag exists, the release contains the commits between the previous matching tag and the tag
+  - if the tag doesn't exist yet (e.g. the release isn't tagged yet), the release contains the commits between the latest matching tag and HEAD, and its date is the date of the HEAD commit
+  - if paths are provided, only the commits that changed any of them are collected
+  - the contributors of the release are first-time contributors if they have no commit reachable from the previous matching tag
+*/
+func CollectRelease(regex, tag string, paths []string) (Release, error) {
+	tags, err := GitRepo.GetTags(regex)
+	if err != nil {
+		return Release{}, err
+	}
+
+	r := Release{Tag: tag}
+	var from, to string
+	for i, t := range tags {
+		if t.Name != tag {
+			continue
+		}
+		r.Date = t.Date
+		to = t.Name
+		if i+1 < len(tags) {
+			from = tags[i+1].Name
+		}
+	}
+	if to == "" {
+		to = "HEAD"
+		if len(tags) > 0 {
+			from = tags[0].Name
+		}
+		date, err := GitRepo.GetCommitDate(to)
+		if err != nil {
+			return Release{}, err
+		}
+		if r.Date, err = time.Parse(time.RFC3339, date); err != nil {
+			return Release{}, fmt.Errorf("unable to parse the date %q of %q: %v", date, to, err)
+		}
+	}
+
+	r.Previous = from
+	if r.Commits, err = collectCommits(from, to, paths); err != nil {
+		return Release{}, err
+	}
+	known, err := knownContributors(from, paths)
+	if err != nil {
+		return Release{}, err
+	}
+	r.Contributors = contributors(r.Commits, known)
+
+	output.Logger().WithFields(logrus.Fields{
+		"changelogRelease":     tag,
+		"changelogReleaseFrom": from,
+		"changelogReleaseTo":   to,
+		"changelogCommits":     len(r.Commits),
+	}).Debug("collected the release for the changelog")
+	return r, nil
+}
+
+/*
+Insert the rendered section of a release below the insert marker; the rest of the changelog is left untouched
+  - if the changelog is empty, a new changelog with a header and the insert marker is created
+  - if the changelog already contains the release, it is returned unchanged, so that inserting the same release twice has no effect
+*/
+func Insert(contents, tag, section string) (string, error) {
+	if contents == "" {
+		contents = newChangelogHeader + InsertMarker + "\n"
+	}
+	if HasRelease(contents, tag) {
+		output.Logger().WithField("changelogRelease", tag).Info("the changelog already contains the release")
+		return contents, nil
+	}
+
+	i := strings.Index(contents, InsertMarker)
+	if i < 0 {
+		return "", fmt.Errorf("%v: marker=%q", ErrInsertMarkerNotFound, InsertMarker)
+	}
+	end := i + len(InsertMarker)
+	if nl := strings.Index(contents[end:], "\n"); nl >= 0 {
+		end += nl + 1
+	} else {
+		contents += "\n"
+		end = len(contents)
+	}
+
+	inserted := "\n" + fmt.Sprintf(releaseMarkerFormat, tag) + "\n" + section
+	if !strings.HasSuffix(inserted, "\n") {
+		inserted += "\n"
+	}
+	return contents[:end] + inserted + contents[end:], nil
+}
+
+// HasRelease checks if a release has already been inserted in the changelog
+func HasRelease(contents, tag string) bool {
+	for _, line := range strings.Split(contents, "\n") {
+		if strings.TrimSpace(line) == fmt.Sprintf(releaseMarkerFormat, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package changelog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_CollectRelease(t *testing.T) {
+	// arrange
+	history := testHistory()
+	history.commits["v1.1.0..HEAD"] = history.commits["..v1.0.0"]
+	GitRepo = history
+	tables := []struct {
+		tag string
+
+		wantDate    string
+		wantCommits int
+	}{
+		{"v1.1.0", "2021-06-03T08:00:00Z", 5},
+		{"v1.0.0", "2021-06-01T08:00:00Z", 1},
+		{"v1.2.0", "2021-06-05T08:00:00Z", 1},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("tag=%q", tb.tag), func(t *testing.T) {
+			r, err := CollectRelease(DefaultRegexFormat, tb.tag, nil)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Date.UTC().Format("2006-01-02T15:04:05Z"); got != tb.wantDate {
+				t.Errorf("got date %q want %q", got, tb.wantDate)
+			}
+			if len(r.Commits) != tb.wantCommits {
+				t.Errorf("got %d commits want %d", len(r.Commits), tb.wantCommits)
+			}
+		})
+	}
+}
+
+func Test_Insert(t *testing.T) {
+	// arrange
+	const section = "## v1.1.0\n\n*  new feature\n"
+	const existing = "# Changelog\n\nManual notes of the release manager.\n\n" + InsertMarker + "\n\n<!-- semtag:release v1.0.0 -->\n## v1.0.0\n\n*  edited by hand\n"
+	tables := []struct {
+		name     string
+		contents string
+
+		want      string
+		wantError error
+	}{
+		{"new changelog", "", "# Changelog\n\n" + InsertMarker + "\n\n<!-- semtag:release v1.1.0 -->\n" + section, nil},
+		{"existing changelog", existing,
+			"# Changelog\n\nManual notes of the release manager.\n\n" + InsertMarker + "\n\n<!-- semtag:release v1.1.0 -->\n" + section +
+				"\n<!-- semtag:release v1.0.0 -->\n## v1.0.0\n\n*  edited by hand\n", nil},
+		{"no insert marker", "# Changelog\n", "", ErrInsertMarkerNotFound},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.name, func(t *testing.T) {
+			got, err := Insert(tb.contents, "v1.1.0", section)
+
+			// assert
+			if tb.wantError != nil {
+				if err == nil || !strings.Contains(err.Error(), tb.wantError.Error()) {
+					t.Fatalf("got error %v want %q", err, tb.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tb.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tb.want)
+			}
+			again, err := Insert(got, "v1.1.0", section)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Errorf("got:\n%s\nwant the release to be inserted only once", again)
+			}
+		})
+	}
+}
+
+func Test_RenderIncrementalFormat(t *testing.T) {
+	// arrange
+	GitRepo = testHistory()
+	tables := []struct {
+		format string
+
+		wantPrefix string
+		wantError  error
+	}{
+		{FormatMarkdown, "# Changelog\n\n" + InsertMarker + "\n\n<!-- semtag:release v1.1.0 -->\n## v1.1.0\n", nil},
+		{FormatKeepAChangelog, "# Changelog\n\nAll notable changes", nil},
+		{FormatHtml, "<h1>Changelog</h1>\n\n" + InsertMarker + "\n\n<!-- semtag:release v1.1.0 -->\n<section class=\"release\">\n  <h2>v1.1.0 ", nil},
+		{FormatJson, "", ErrIncrementalFormat},
+		{FormatAsciidoc, "", ErrIncrementalFormat},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("format=%q", tb.format), func(t *testing.T) {
+			l := Log{Format: tb.format, Incremental: true, Version: "v1.1.0", File: file{name: filepath.Join(t.TempDir(), "CHANGELOG")}}
+			got, err := l.Render()
+
+			// assert
+			if tb.wantError != nil {
+				if err == nil || !strings.Contains(err.Error(), tb.wantError.Error()) {
+					t.Fatalf("got error %v want %q", err, tb.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(got, tb.wantPrefix) {
+				t.Errorf("got:\n%s\nwant prefix:\n%s", got, tb.wantPrefix)
+			}
+			if !strings.Contains(got, "v1.1.0") || strings.Contains(got, "v1.0.0") {
+				t.Errorf("got:\n%s\nwant only the release v1.1.0", got)
+			}
+		})
+	}
+}
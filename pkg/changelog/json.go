@@ -0,0 +1,166 @@
+package changelog
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+const (
+	// JsonSchemaVersion is the version of the schema of the JSON changelog; it is increased if a field is removed or changes its meaning
+	JsonSchemaVersion = 1
+)
+
+// Json renders the changelog as a JSON document; the schema is versioned, so that the document can be consumed by other tools
+type Json struct {
+	Links Links
+	// Sections of a release; the default sections are used if none is set
+	Sections Sections
+}
+
+type jsonChangelog struct {
+	SchemaVersion int           `json:"schemaVersion"`
+	Unreleased    jsonRelease   `json:"unreleased"`
+	Releases      []jsonRelease `json:"releases"`
+}
+
+type jsonRelease struct {
+	Tag          string            `json:"tag"`
+	PreviousTag  string            `json:"previousTag"`
+	Date         *time.Time        `json:"date"`
+	Url          string            `json:"url"`
+	CompareUrl   string            `json:"compareUrl"`
+	Sections     []jsonSection     `json:"sections"`
+	Commits      []jsonCommit      `json:"commits"`
+	References   []jsonReference   `json:"references"`
+	Contributors []jsonContributor `json:"contributors"`
+}
+
+// jsonSection refers to the commits of the release by hash
+type jsonSection struct {
+	Title    string   `json:"title"`
+	Breaking bool     `json:"breaking"`
+	Commits  []string `json:"commits"`
+}
+
+type jsonCommit struct {
+	Hash           string          `json:"hash"`
+	ShortHash      string          `json:"shortHash"`
+	Url            string          `json:"url"`
+	Date           time.Time       `json:"date"`
+	Author         jsonAuthor      `json:"author"`
+	CoAuthors      []jsonAuthor    `json:"coAuthors"`
+	Subject        string          `json:"subject"`
+	Type           string          `json:"type"`
+	Scope          string          `json:"scope"`
+	Description    string          `json:"description"`
+	Body           string          `json:"body"`
+	Breaking       bool            `json:"breaking"`
+	BreakingChange string          `json:"breakingChange"`
+	References     []jsonReference `json:"references"`
+}
+
+type jsonReference struct {
+	Text   string `json:"text"`
+	Id     string `json:"id"`
+	Url    string `json:"url"`
+	Closed bool   `json:"closed"`
+}
+
+type jsonAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type jsonContributor struct {
+	jsonAuthor
+	FirstTime bool `json:"firstTime"`
+}
+
+func (j Json) Render(w io.Writer, cl Changelog) error {
+	data := NewTemplateData(cl, j.Links, j.Sections)
+	doc := jsonChangelog{
+		SchemaVersion: JsonSchemaVersion,
+		Unreleased:    newJsonRelease(data.Unreleased),
+		Releases:      []jsonRelease{},
+	}
+	for _, r := range data.Releases {
+		doc.Releases = append(doc.Releases, newJsonRelease(r))
+	}
+	return j.encode(w, doc)
+}
+
+// RenderRelease renders a single release as a JSON document with the schema version
+func (j Json) RenderRelease(w io.Writer, r Release) error {
+	doc := struct {
+		SchemaVersion int `json:"schemaVersion"`
+		jsonRelease
+	}{JsonSchemaVersion, newJsonRelease(newTemplateRelease(r, j.Links, j.Sections))}
+	return j.encode(w, doc)
+}
+
+func (j Json) encode(w io.Writer, doc interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// newJsonRelease converts a release of the data model; the lists are never null and the date is null for the unreleased commits
+func newJsonRelease(r TemplateRelease) jsonRelease {
+	jr := jsonRelease{
+		Tag:          r.Tag,
+		PreviousTag:  r.PreviousTag,
+		Url:          r.Url,
+		CompareUrl:   r.CompareUrl,
+		Sections:     []jsonSection{},
+		Commits:      []jsonCommit{},
+		References:   newJsonReferences(r.References),
+		Contributors: []jsonContributor{},
+	}
+	if !r.Date.IsZero() {
+		date := r.Date.UTC()
+		jr.Date = &date
+	}
+	for _, s := range r.Sections {
+		js := jsonSection{Title: s.Title, Breaking: s.Breaking, Commits: []string{}}
+		for _, c := range s.Commits {
+			js.Commits = append(js.Commits, c.Hash)
+		}
+		jr.Sections = append(jr.Sections, js)
+	}
+	for _, c := range r.Contributors {
+		jr.Contributors = append(jr.Contributors, jsonContributor{jsonAuthor{Name: c.Name, Email: c.Email}, c.FirstTime})
+	}
+	for _, c := range r.Commits {
+		coAuthors := []jsonAuthor{}
+		for _, co := range c.CoAuthors {
+			coAuthors = append(coAuthors, jsonAuthor{Name: co.Name, Email: co.Email})
+		}
+		jr.Commits = append(jr.Commits, jsonCommit{
+			Hash:           c.Hash,
+			ShortHash:      c.ShortHash,
+			Url:            c.Url,
+			Date:           c.Date.UTC(),
+			Author:         jsonAuthor{Name: c.Author.Name, Email: c.Author.Email},
+			CoAuthors:      coAuthors,
+			Subject:        c.Subject,
+			Type:           c.Type,
+			Scope:          c.Scope,
+			Description:    c.Description,
+			Body:           c.Body,
+			Breaking:       c.Breaking,
+			BreakingChange: c.BreakingChange,
+			References:     newJsonReferences(c.References),
+		})
+	}
+	return jr
+}
+
+func newJsonReferences(refs []TemplateReference) []jsonReference {
+	out := []jsonReference{}
+	for _, r := range refs {
+		out = append(out, jsonReference{Text: r.Text, Id: r.Id, Url: r.Url, Closed: r.Closed})
+	}
+	return out
+}
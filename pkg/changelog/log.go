@@ -2,13 +2,28 @@ package changelog
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
-	"semtag/pkg/terminal"
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/output"
+	"semtag/pkg/remote"
+	"semtag/pkg/version"
 )
 
 const (
-	EnvVarGitCommitUrl = "GIT_COMMIT_URL"
-	EnvVarGitTagUrl    = "GIT_TAG_URL"
+	// EnvVarGitCommitUrl, EnvVarGitTagUrl, EnvVarGitCompareUrl, EnvVarGitIssueUrl and EnvVarGitMergeRequestUrl override the URLs derived from the remote of the repository (see Links)
+	EnvVarGitCommitUrl       = "GIT_COMMIT_URL"
+	EnvVarGitTagUrl          = "GIT_TAG_URL"
+	EnvVarGitCompareUrl      = "GIT_COMPARE_URL"
+	EnvVarGitIssueUrl        = "GIT_ISSUE_URL"
+	EnvVarGitMergeRequestUrl = "GIT_MERGE_REQUEST_URL"
+
+	// DefaultRemote is the git remote from which the URLs of the hyperlinks are derived
+	DefaultRemote = "origin"
 
 	DefaultRegexFormat = `^%s[0-9]+\.[0-9]+\.[0-9]+%s$`
 )
@@ -26,64 +41,284 @@ type Log struct {
 	Regex string
 	// File name for the changelog
 	File file
+	// Paths restricts the changelog to the commits that changed any of them (e.g. the paths of a component of a monorepo); all the commits are included if it is empty
+	Paths []string
+	// Dir is the directory of the default changelog file (e.g. services/api/CHANGELOG.md); the default is the current directory
+	Dir string
+	// Sections of a release, in the order in which they are rendered
+	Sections Sections
+	// Incremental inserts only the release of Version below the insert marker of the existing changelog, instead of regenerating the whole changelog
+	Incremental bool
+	// Version is the tag of the release inserted in incremental mode
+	Version string
+	// Format is the name of the built-in format of the changelog (e.g. keep-a-changelog); the default is Markdown
+	Format string
+	// TemplateFile is a text/template file used to render the changelog instead of the template of the built-in format
+	TemplateFile string
+
+	// Package contains the metadata of the Debian and RPM packages, for the package formats (e.g. debian)
+	Package Package
 
-	// urlCommit is used to generating hyperlinks
-	urlCommit string
-	// urlTag is used to generating hyperlinks
-	urlTag string
+	// HostType overrides the git host type detected from the remote URL (e.g. gitlab for a self-hosted instance)
+	HostType string
+	// HostUrl overrides the web URL of the repository derived from the remote URL (e.g. https://git.example.com/org/repo)
+	HostUrl string
+	// References configures the linking of the references to issues, merge requests and tickets in the commit messages
+	References References
+	// Emails is true if the email addresses of the authors and the contributors are published in the changelog; they are removed by default
+	Emails bool
 }
 
-func NewLog() (Log, error) {
-	log := Log{}
+func NewLog() Log {
+	return Log{File: file{}}
+}
 
-	log.File = file{}
-	log.setFileName()
-	if err := log.setCommitUrl(); err != nil {
-		return Log{}, err
+// Generate the changelog and write it to the file
+func (l *Log) Generate() error {
+	contents, err := l.Render()
+	if err != nil {
+		return err
 	}
-	if err := log.setTagUrl(); err != nil {
-		return Log{}, err
+	l.setFileName()
+	return l.File.Write(contents)
+}
+
+// Render the changelog without writing it: the releases are collected from git and rendered in the format of the changelog
+func (l *Log) Render() (string, error) {
+	if l.Incremental {
+		return l.renderIncremental()
 	}
-	return log, nil
+	cl, err := Collect(l.tagRegex(), l.Paths)
+	if err != nil {
+		return "", err
+	}
+	if !l.Emails {
+		cl = cl.WithoutEmails()
+	}
+	return l.render(cl)
 }
 
-// Generate the changelog
-func (l *Log) Generate() error {
-	l.setRegex()
-	if err := l.File.Generate(l.File.name, l.Regex, l.urlCommit, l.urlTag); err != nil {
-		return err
+// renderIncremental inserts the release of the version in the existing changelog
+func (l *Log) renderIncremental() (string, error) {
+	renderer, err := l.renderer()
+	if err != nil {
+		return "", err
+	}
+	var existing string
+	if _, err := os.Stat(l.FileName()); err == nil {
+		f := version.File{Path: l.FileName()}
+		dat, err := f.Read()
+		if err != nil {
+			return "", err
+		}
+		existing = string(dat)
+	}
+	if p, ok := renderer.(Prepender); ok {
+		return l.prepend(p, existing)
+	}
+	rr, ok := renderer.(IncrementalRenderer)
+	if !ok {
+		return "", fmt.Errorf("%v: format=%q", ErrIncrementalFormat, l.formatName())
+	}
+
+	if HasRelease(existing, l.Version) {
+		return Insert(existing, l.Version, "")
+	}
+	if existing == "" {
+		var b strings.Builder
+		if err := rr.RenderHeader(&b); err != nil {
+			return "", err
+		}
+		existing = b.String() + InsertMarker + "\n"
+	}
+
+	r, err := l.collectRelease()
+	if err != nil {
+		return "", err
+	}
+	var section strings.Builder
+	if err := rr.RenderRelease(&section, r); err != nil {
+		return "", err
+	}
+	return Insert(existing, l.Version, section.String())
+}
+
+// prepend inserts the release of the version in the existing changelog with the syntax of its format; the changelog is unchanged if it already contains the release
+func (l *Log) prepend(p Prepender, existing string) (string, error) {
+	if p.HasRelease(existing, l.Version) {
+		output.Logger().WithField("changelogRelease", l.Version).Info("the changelog already contains the release")
+		return existing, nil
+	}
+	r, err := l.collectRelease()
+	if err != nil {
+		return "", err
+	}
+	return p.Prepend(existing, r)
+}
+
+// RenderRelease renders the release notes of the version: only the release of the version is collected (see CollectRelease) and rendered in the format of the changelog
+func (l *Log) RenderRelease() (string, error) {
+	renderer, err := l.renderer()
+	if err != nil {
+		return "", err
+	}
+	rr, ok := renderer.(ReleaseRenderer)
+	if !ok {
+		return "", fmt.Errorf("%v: format=%q", ErrReleaseFormat, l.formatName())
+	}
+	r, err := l.collectRelease()
+	if err != nil {
+		return "", err
 	}
-	return nil
+	var b strings.Builder
+	if err := rr.RenderRelease(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// collectRelease collects the release of the version; the email addresses are removed unless they are published
+func (l *Log) collectRelease() (Release, error) {
+	r, err := CollectRelease(l.tagRegex(), l.Version, l.Paths)
+	if err != nil || l.Emails {
+		return r, err
+	}
+	return r.WithoutEmails(), nil
+}
+
+func (l *Log) render(cl Changelog) (string, error) {
+	renderer, err := l.renderer()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := renderer.Render(&b, cl); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+/*
+renderer returns the renderer of the changelog:
+  - if a template file is set, the template is used
+  - otherwise the built-in format is used; in incremental mode, the format must support it
+  - the package formats (e.g. debian) are rendered with the package metadata; the versions have no tag prefix
+*/
+func (l *Log) renderer() (Renderer, error) {
+	links := l.links()
+	if l.TemplateFile != "" {
+		t, err := LoadTemplate(l.TemplateFile)
+		if err != nil {
+			return nil, err
+		}
+		return Template{Template: t, Links: links, Sections: l.Sections}, nil
+	}
+
+	f, err := FindFormat(l.formatName())
+	if err != nil {
+		return nil, err
+	}
+	if l.Incremental && !f.Incremental {
+		return nil, fmt.Errorf("%v: format=%q", ErrIncrementalFormat, f.Name)
+	}
+	if f.Package {
+		p := l.Package
+		p.Prefix = l.Prefix
+		return f.PackageRenderer(p, l.Sections), nil
+	}
+	return f.Renderer(links, l.Sections), nil
 }
 
-func (l *Log) setRegex() {
-	if l.Regex == "" {
-		l.Regex = DefaultRegexFormat
+func (l *Log) formatName() string {
+	if l.Format == "" {
+		return DefaultFormat
 	}
-	l.Regex = fmt.Sprintf(l.Regex, l.Prefix, l.Suffix)
+	return l.Format
+}
+
+// SetFile sets the changelog file instead of the default file of the format
+func (l *Log) SetFile(name string) {
+	l.File.name = name
+}
+
+// FileName returns the name of the changelog file; the default depends on the format (e.g. CHANGELOG.json) and is in Dir
+func (l *Log) FileName() string {
+	l.setFileName()
+	return l.File.name
+}
+
+// tagRegex returns the regex of the version tags: the prefix and the suffix are inserted in the regex format
+func (l *Log) tagRegex() string {
+	format := l.Regex
+	if format == "" {
+		format = DefaultRegexFormat
+	}
+	return fmt.Sprintf(format, regexp.QuoteMeta(l.Prefix), regexp.QuoteMeta(l.Suffix))
 }
 
 func (l *Log) setFileName() {
 	if l.File.name != "" {
 		return
 	}
-	l.File.name = DefaultChangelogFile
+	name := DefaultChangelogFile
+	if f, err := FindFormat(l.formatName()); err == nil {
+		name = f.FileName
+	}
+	l.File.name = filepath.Join(l.Dir, name)
 }
 
-func (l *Log) setTagUrl() error {
-	url, err := terminal.GetEnv(EnvVarGitTagUrl)
-	if err != nil {
-		return err
+/*
+links returns the URLs of the hyperlinks of the changelog:
+  - the URLs are derived from the remote of the repository if its host type is known or set (GitHub, GitLab, Bitbucket, Gitea)
+  - the environment variables (e.g. GIT_COMMIT_URL) override the derived URLs
+  - if a URL can't be derived and isn't set, the changelog has no such hyperlinks
+*/
+func (l *Log) links() Links {
+	var derived remote.Links
+	if repo, err := l.repository(); err != nil {
+		output.Logger().WithField("err", err).Info("unable to derive the hyperlinks of the changelog from the git remote")
+	} else if repo.HostType == "" {
+		output.Logger().WithField("remoteHost", repo.Host).Info("unknown git host type, the hyperlinks of the changelog can't be derived from the git remote")
+	} else {
+		derived = repo.Links()
+		output.Logger().WithFields(logrus.Fields{
+			"remoteHost":     repo.Host,
+			"remoteHostType": repo.HostType,
+			"remoteWebUrl":   repo.WebUrl,
+		}).Debug("derived the hyperlinks of the changelog from the git remote")
+	}
+
+	getEnv := func(key, fallback string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return fallback
+	}
+	return Links{
+		CommitUrl:       getEnv(EnvVarGitCommitUrl, derived.Commit),
+		TagUrl:          getEnv(EnvVarGitTagUrl, derived.Tag),
+		CompareUrl:      getEnv(EnvVarGitCompareUrl, derived.Compare),
+		IssueUrl:        getEnv(EnvVarGitIssueUrl, derived.Issue),
+		MergeRequestUrl: getEnv(EnvVarGitMergeRequestUrl, derived.MergeRequest),
+		References:      l.References,
 	}
-	l.urlTag = url
-	return nil
 }
 
-func (l *Log) setCommitUrl() error {
-	url, err := terminal.GetEnv(EnvVarGitCommitUrl)
+// repository returns the hosted repository of the remote, with the overrides of the host type and of the web URL
+func (l *Log) repository() (remote.Repository, error) {
+	remoteUrl := l.HostUrl
+	if remoteUrl == "" {
+		var err error
+		if remoteUrl, err = GitRepo.GetRemoteUrl(DefaultRemote); err != nil {
+			return remote.Repository{}, err
+		}
+	}
+	repo, err := remote.ParseUrl(remoteUrl)
 	if err != nil {
-		return err
+		return remote.Repository{}, err
+	}
+	if l.HostType != "" {
+		repo.HostType = l.HostType
 	}
-	l.urlCommit = url
-	return nil
+	return repo, nil
 }
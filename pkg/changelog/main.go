@@ -0,0 +1,5 @@
+package changelog
+
+import "semtag/pkg/versionControl"
+
+var GitRepo versionControl.VersionControl = &versionControl.GitRepository{}
@@ -0,0 +1,299 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// EnvVarDebFullName and EnvVarDebEmail are the maintainer of the packages if it isn't set (the convention of the Debian tools, e.g. dch)
+	EnvVarDebFullName = "DEBFULLNAME"
+	EnvVarDebEmail    = "DEBEMAIL"
+
+	DefaultPackageDistribution = "unstable"
+	DefaultPackageUrgency      = "medium"
+	DefaultPackageRelease      = "1"
+
+	// rpmChangelogSection is the line of a spec file below which the entries of the changelog are listed
+	rpmChangelogSection = "%changelog"
+
+	// debianDateLayout is the RFC 2822 date of the trailer line of a Debian changelog entry
+	debianDateLayout = "Mon, 02 Jan 2006 15:04:05 -0700"
+	// rpmDateLayout is the date of the header line of a RPM changelog entry
+	rpmDateLayout = "Mon Jan 02 2006"
+
+	// packageLineWidth is the maximum width of the lines of the changes; longer changes are wrapped
+	packageLineWidth = 80
+)
+
+var (
+	ErrInvalidPackage = errors.New("invalid package metadata")
+
+	// debianUrgencies are the urgencies allowed by the Debian policy
+	debianUrgencies = []string{"low", "medium", "high", "emergency", "critical"}
+
+	// debianPackageNameRegex is the syntax of a Debian source package name
+	debianPackageNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+$`)
+	// debianHeaderRegex matches the first line of a Debian changelog entry: package (version) distribution; urgency=urgency
+	debianHeaderRegex = regexp.MustCompile(`^(\S+) \(([^()\s]+)\) [^;]+;`)
+	// rpmHeaderRegex matches the first line of a RPM changelog entry: * date maintainer - version-release
+	rpmHeaderRegex = regexp.MustCompile(`^\* .* - (\S+)$`)
+)
+
+// Package contains the metadata of the Debian and RPM packages built from the releases
+type Package struct {
+	// Name of the (source) package
+	Name string
+	// Maintainer of the package, with the format: Name <email>; the default is DEBFULLNAME <DEBEMAIL>
+	Maintainer string
+	// Distribution of the Debian package (e.g. unstable, bookworm)
+	Distribution string
+	// Urgency of the Debian upload: low, medium, high, emergency or critical
+	Urgency string
+	// Release of the RPM package, appended to the version (e.g. 1.2.0-1)
+	Release string
+	// Prefix of the version tags, removed from the package versions (e.g. v)
+	Prefix string
+}
+
+// withDefaults returns the package with the default distribution, urgency, release and maintainer
+func (p Package) withDefaults() Package {
+	if p.Distribution == "" {
+		p.Distribution = DefaultPackageDistribution
+	}
+	if p.Urgency == "" {
+		p.Urgency = DefaultPackageUrgency
+	}
+	if p.Release == "" {
+		p.Release = DefaultPackageRelease
+	}
+	if p.Maintainer == "" && os.Getenv(EnvVarDebFullName) != "" && os.Getenv(EnvVarDebEmail) != "" {
+		p.Maintainer = fmt.Sprintf("%s <%s>", os.Getenv(EnvVarDebFullName), os.Getenv(EnvVarDebEmail))
+	}
+	return p
+}
+
+// Validate checks the metadata required by the strict formats of the Debian and RPM changelogs
+func (p Package) Validate() error {
+	p = p.withDefaults()
+	if !debianPackageNameRegex.MatchString(p.Name) {
+		return fmt.Errorf("%v: name=%q: expected lower case letters, digits and +.- (at least 2 characters)", ErrInvalidPackage, p.Name)
+	}
+	if id := parseContact(p.Maintainer); id.Name == "" || id.Email == "" || !strings.Contains(id.Email, "@") {
+		return fmt.Errorf("%v: maintainer=%q: expected Name <email> (or the environment variables %s and %s)", ErrInvalidPackage, p.Maintainer, EnvVarDebFullName, EnvVarDebEmail)
+	}
+	if strings.ContainsAny(p.Distribution, " ;()") {
+		return fmt.Errorf("%v: distribution=%q", ErrInvalidPackage, p.Distribution)
+	}
+	valid := false
+	for _, u := range debianUrgencies {
+		valid = valid || p.Urgency == u
+	}
+	if !valid {
+		return fmt.Errorf("%v: urgency=%q, urgencies=%q", ErrInvalidPackage, p.Urgency, debianUrgencies)
+	}
+	if strings.ContainsAny(p.Release, " -") {
+		return fmt.Errorf("%v: release=%q", ErrInvalidPackage, p.Release)
+	}
+	return nil
+}
+
+// version converts a tag to a package version: the prefix is removed and the pre-release is separated with ~, so that it sorts before the release (e.g. v1.2.0-rc.1 is 1.2.0~rc.1)
+func (p Package) version(tag string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(tag, p.Prefix), "-", "~")
+}
+
+/*
+changes returns the changes of a release, one per commit in the order of the sections; a commit listed in several sections (e.g. a breaking change) is listed once
+  - the breaking changes are prefixed with BREAKING:
+  - a release without commits has a single change, since the formats require at least one
+*/
+func changes(r Release, sections Sections, version string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, s := range sections.OrDefault().Group(r.Commits) {
+		for _, c := range s.Commits {
+			if seen[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+			change := c.Message.Description
+			if c.Message.Scope != "" {
+				change = c.Message.Scope + ": " + change
+			}
+			if c.Message.Breaking {
+				change = "BREAKING: " + change
+			}
+			out = append(out, change)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, fmt.Sprintf("Release %s", version))
+	}
+	return out
+}
+
+// wrap writes a change as a list item: the first line starts with the bullet and the next lines are indented to the text of the bullet
+func wrap(b *strings.Builder, bullet, change string) {
+	line := bullet
+	for _, word := range strings.Fields(change) {
+		if len(line) > len(bullet) && len(line)+1+len(word) > packageLineWidth {
+			b.WriteString(line + "\n")
+			line = strings.Repeat(" ", len(bullet))
+		}
+		line += " " + word
+	}
+	b.WriteString(line + "\n")
+}
+
+// Debian renders the changelog with the format of debian/changelog (https://www.debian.org/doc/debian-policy/ch-source.html#debian-changelog-debian-changelog); the unreleased commits are skipped
+type Debian struct {
+	Package Package
+	// Sections of a release; the default sections are used if none is set
+	Sections Sections
+}
+
+func (d Debian) Render(w io.Writer, cl Changelog) error {
+	for i, r := range cl.Releases {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := d.RenderRelease(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderRelease renders a changelog entry: the header line, the changes and the trailer line with the maintainer and the RFC 2822 date
+func (d Debian) RenderRelease(w io.Writer, r Release) error {
+	p := d.Package.withDefaults()
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	version := fmt.Sprintf("%s-%s", p.version(r.Tag), p.Release)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s) %s; urgency=%s\n\n", p.Name, version, p.Distribution, p.Urgency)
+	for _, change := range changes(r, d.Sections, version) {
+		wrap(&b, "  *", change)
+	}
+	fmt.Fprintf(&b, "\n -- %s  %s\n", p.Maintainer, r.Date.Format(debianDateLayout))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HasRelease checks if the Debian changelog contains an entry of the version of the tag
+func (d Debian) HasRelease(contents, tag string) bool {
+	p := d.Package.withDefaults()
+	for _, line := range strings.Split(contents, "\n") {
+		if m := debianHeaderRegex.FindStringSubmatch(line); m != nil && strings.HasPrefix(m[2], p.version(tag)+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// Prepend inserts the entry of the release at the top of the Debian changelog
+func (d Debian) Prepend(contents string, r Release) (string, error) {
+	var b strings.Builder
+	if err := d.RenderRelease(&b, r); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(contents) == "" {
+		return b.String(), nil
+	}
+	return b.String() + "\n" + contents, nil
+}
+
+// Rpm renders the %changelog section of a RPM spec file (https://rpm-software-management.github.io/rpm/manual/spec.html); the unreleased commits are skipped
+type Rpm struct {
+	Package Package
+	// Sections of a release; the default sections are used if none is set
+	Sections Sections
+}
+
+func (rp Rpm) Render(w io.Writer, cl Changelog) error {
+	if _, err := io.WriteString(w, rpmChangelogSection+"\n"); err != nil {
+		return err
+	}
+	for i, r := range cl.Releases {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := rp.RenderRelease(w, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderRelease renders a changelog entry: the header line with the date, the maintainer and the version-release, then the changes
+func (rp Rpm) RenderRelease(w io.Writer, r Release) error {
+	p := rp.Package.withDefaults()
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	version := fmt.Sprintf("%s-%s", p.version(r.Tag), p.Release)
+	var b strings.Builder
+	fmt.Fprintf(&b, "* %s %s - %s\n", r.Date.Format(rpmDateLayout), p.Maintainer, version)
+	for _, change := range changes(r, rp.Sections, version) {
+		// rpm expands the macros in the %changelog section: a literal % is escaped as %%
+		wrap(&b, "-", strings.ReplaceAll(change, "%", "%%"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HasRelease checks if the RPM changelog contains an entry of the version of the tag
+func (rp Rpm) HasRelease(contents, tag string) bool {
+	p := rp.Package.withDefaults()
+	for _, line := range strings.Split(contents, "\n") {
+		if m := rpmHeaderRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil && strings.HasPrefix(m[1], p.version(tag)+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Prepend inserts the entry of the release below the %changelog line of a spec file (or of a changelog file), above the previous entries
+  - if the file is empty, a new %changelog section is created
+  - if the spec file has no %changelog section, it is appended at the end of the file
+*/
+func (rp Rpm) Prepend(contents string, r Release) (string, error) {
+	var b strings.Builder
+	if err := rp.RenderRelease(&b, r); err != nil {
+		return "", err
+	}
+	entry := b.String()
+
+	lines := strings.SplitAfter(contents, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != rpmChangelogSection {
+			continue
+		}
+		rest := strings.Join(lines[i+1:], "")
+		if strings.TrimSpace(rest) != "" {
+			entry += "\n"
+		}
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		return strings.Join(lines[:i], "") + line + entry + rest, nil
+	}
+	if strings.TrimSpace(contents) == "" {
+		return rpmChangelogSection + "\n" + entry, nil
+	}
+	if !strings.HasSuffix(contents, "\n") {
+		contents += "\n"
+	}
+	return contents + "\n" + rpmChangelogSection + "\n" + entry, nil
+}
@@ -0,0 +1,225 @@
+package changelog
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	// the lines of a Debian changelog entry: header, blank line, changes, blank line, trailer
+	debianHeaderLineRegex  = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+ \([0-9][A-Za-z0-9.+~-]*-[A-Za-z0-9.+~]+\) [a-z0-9-]+; urgency=(low|medium|high|emergency|critical)$`)
+	debianChangeLineRegex  = regexp.MustCompile(`^(  \* |    )\S.*$`)
+	debianTrailerLineRegex = regexp.MustCompile(`^ -- [^<>]+ <[^<>@]+@[^<>]+>  (.+)$`)
+	// the lines of a RPM changelog entry: header, then changes
+	rpmHeaderLineRegex = regexp.MustCompile(`^\* ((?:Mon|Tue|Wed|Thu|Fri|Sat|Sun) (?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) [0-9]{2} [0-9]{4}) [^<>]+ <[^<>@]+@[^<>]+> - [0-9][A-Za-z0-9.+~]*-[A-Za-z0-9.+~]+$`)
+	rpmChangeLineRegex = regexp.MustCompile(`^(- |  )\S.*$`)
+)
+
+func testPackage() Package {
+	return Package{Name: "my-app", Maintainer: "Jane Doe <jane@example.com>", Prefix: "v"}
+}
+
+// validateDebian checks the syntax of a Debian changelog (Debian policy 4.4) and returns the dates of the entries
+func validateDebian(contents string) ([]time.Time, error) {
+	var dates []time.Time
+	state := "header"
+	for i, line := range strings.Split(strings.TrimSuffix(contents, "\n"), "\n") {
+		switch {
+		case state == "header" && debianHeaderLineRegex.MatchString(line):
+			state = "blank"
+		case state == "blank" && line == "":
+			state = "change"
+		case state == "change" && debianChangeLineRegex.MatchString(line) && len(line) <= packageLineWidth:
+			state = "changes"
+		case state == "changes" && debianChangeLineRegex.MatchString(line) && len(line) <= packageLineWidth:
+		case state == "changes" && line == "":
+			state = "trailer"
+		case state == "trailer" && debianTrailerLineRegex.MatchString(line):
+			date, err := time.Parse(time.RFC1123Z, debianTrailerLineRegex.FindStringSubmatch(line)[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q: %v", i+1, line, err)
+			}
+			dates = append(dates, date)
+			state = "separator"
+		case state == "separator" && line == "":
+			state = "header"
+		default:
+			return nil, fmt.Errorf("line %d: %q: expected %s", i+1, line, state)
+		}
+	}
+	if state != "separator" {
+		return nil, fmt.Errorf("unexpected end of changelog: expected %s", state)
+	}
+	return dates, nil
+}
+
+// validateRpm checks the syntax of the %changelog section of a spec file and returns the dates of the entries
+func validateRpm(contents string) ([]time.Time, error) {
+	var dates []time.Time
+	state := "section"
+	for i, line := range strings.Split(strings.TrimSuffix(contents, "\n"), "\n") {
+		switch {
+		case state == "section" && line == rpmChangelogSection:
+			state = "header"
+		case (state == "header" || state == "changes") && rpmHeaderLineRegex.MatchString(line):
+			date, err := time.Parse(rpmDateLayout, rpmHeaderLineRegex.FindStringSubmatch(line)[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q: %v", i+1, line, err)
+			}
+			dates = append(dates, date)
+			state = "change"
+		case (state == "change" || state == "changes") && rpmChangeLineRegex.MatchString(line) && len(line) <= packageLineWidth:
+			if strings.Contains(strings.ReplaceAll(line, "%%", ""), "%") {
+				return nil, fmt.Errorf("line %d: %q: unescaped %%", i+1, line)
+			}
+			state = "changes"
+		case state == "changes" && line == "":
+			state = "header"
+		default:
+			return nil, fmt.Errorf("line %d: %q: expected %s", i+1, line, state)
+		}
+	}
+	if state != "changes" {
+		return nil, fmt.Errorf("unexpected end of changelog: expected %s", state)
+	}
+	return dates, nil
+}
+
+func Test_PackageFormats(t *testing.T) {
+	// arrange
+	history := testHistory()
+	history.commits["v1.0.0..v1.1.0"][0].Subject = "fix(api): handle 100% of the requests even if the upstream server is slow to answer or closes the connection"
+	GitRepo = history
+	tables := []struct {
+		format   string
+		validate func(string) ([]time.Time, error)
+
+		wantChange string
+	}{
+		{FormatDebian, validateDebian, "api: handle 100% of the requests"},
+		{FormatRpm, validateRpm, "api: handle 100%% of the requests"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("format=%q", tb.format), func(t *testing.T) {
+			l := Log{Format: tb.format, Prefix: "v", Package: testPackage()}
+			got, err := l.Render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			dates, err := tb.validate(got)
+
+			// assert
+			if err != nil {
+				t.Fatalf("got:\n%s\ninvalid changelog: %v", got, err)
+			}
+			if len(dates) != 2 {
+				t.Errorf("got %d entries want 2", len(dates))
+			}
+			for i := 1; i < len(dates); i++ {
+				if dates[i].After(dates[i-1]) {
+					t.Errorf("got entry %d newer than the previous entry: %v > %v", i, dates[i], dates[i-1])
+				}
+			}
+			if strings.Contains(got, "v1.1.0") || !strings.Contains(got, "1.1.0-1") {
+				t.Errorf("got:\n%s\nwant the versions without prefix and with the package release", got)
+			}
+			if !strings.Contains(got, tb.wantChange) {
+				t.Errorf("got:\n%s\nwant the change %q", got, tb.wantChange)
+			}
+		})
+	}
+}
+
+func Test_PackagePrepend(t *testing.T) {
+	// arrange
+	GitRepo = testHistory()
+	release := Release{Tag: "v1.2.0-rc.1", Previous: "v1.1.0", Date: time.Date(2021, time.June, 5, 10, 0, 0, 0, time.UTC)}
+	debian := Debian{Package: testPackage()}
+	rpm := Rpm{Package: testPackage()}
+	var existingDebian, existingRpm strings.Builder
+	if err := debian.Render(&existingDebian, Changelog{Releases: []Release{{Tag: "v1.1.0", Date: release.Date}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rpm.RenderRelease(&existingRpm, Release{Tag: "v1.1.0", Date: release.Date}); err != nil {
+		t.Fatal(err)
+	}
+	spec := "Name: my-app\nVersion: 1.1.0\n\n%description\nMy app.\n"
+	tables := []struct {
+		name      string
+		prepender Prepender
+		existing  string
+
+		wantPrefix string
+		wantSuffix string
+	}{
+		{"debian new", debian, "", "my-app (1.2.0~rc.1-1) unstable; urgency=medium\n\n  * Release 1.2.0~rc.1-1\n\n -- Jane Doe <jane@example.com>  Sat, 05 Jun 2021 10:00:00 +0000\n", ""},
+		{"debian existing", debian, existingDebian.String(), "my-app (1.2.0~rc.1-1) unstable", "\n\n" + existingDebian.String()},
+		{"rpm new", rpm, "", "%changelog\n* Sat Jun 05 2021 Jane Doe <jane@example.com> - 1.2.0~rc.1-1\n- Release 1.2.0~rc.1-1\n", ""},
+		{"rpm existing section", rpm, spec + "\n%changelog\n" + existingRpm.String(), spec + "\n%changelog\n* Sat Jun 05 2021", "\n\n" + existingRpm.String()},
+		{"rpm without section", rpm, spec, spec + "\n%changelog\n* Sat Jun 05 2021", "1.2.0~rc.1-1\n"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.name, func(t *testing.T) {
+			got, err := tb.prepender.Prepend(tb.existing, release)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(got, tb.wantPrefix) || !strings.HasSuffix(got, tb.wantSuffix) {
+				t.Errorf("got:\n%s\nwant prefix:\n%s\nwant suffix:\n%s", got, tb.wantPrefix, tb.wantSuffix)
+			}
+			if !tb.prepender.HasRelease(got, release.Tag) {
+				t.Errorf("got:\n%s\nwant the release %s", got, release.Tag)
+			}
+			if tb.prepender.HasRelease(tb.existing, release.Tag) {
+				t.Errorf("got the release %s in the existing changelog", release.Tag)
+			}
+		})
+	}
+}
+
+func Test_PackageValidate(t *testing.T) {
+	// arrange
+	tables := []struct {
+		pkg Package
+
+		wantErr bool
+	}{
+		{testPackage(), false},
+		{Package{Name: "my-app", Maintainer: "Jane Doe <jane@example.com>", Distribution: "bookworm", Urgency: "high", Release: "2"}, false},
+		{Package{Name: "My_App", Maintainer: "Jane Doe <jane@example.com>"}, true},
+		{Package{Name: "my-app"}, true},
+		{Package{Name: "my-app", Maintainer: "jane@example.com"}, true},
+		{Package{Name: "my-app", Maintainer: "Jane Doe <jane@example.com>", Urgency: "urgent"}, true},
+		{Package{Name: "my-app", Maintainer: "Jane Doe <jane@example.com>", Distribution: "stable; urgency=low"}, true},
+		{Package{Name: "my-app", Maintainer: "Jane Doe <jane@example.com>", Release: "1-1"}, true},
+	}
+
+	if name, ok := os.LookupEnv(EnvVarDebFullName); ok {
+		defer os.Setenv(EnvVarDebFullName, name)
+	} else {
+		defer os.Unsetenv(EnvVarDebFullName)
+	}
+	os.Setenv(EnvVarDebFullName, "")
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("package=%+v", tb.pkg), func(t *testing.T) {
+			err := tb.pkg.Validate()
+
+			// assert
+			if (err != nil) != tb.wantErr {
+				t.Errorf("got error %v want error %v", err, tb.wantErr)
+			}
+		})
+	}
+}
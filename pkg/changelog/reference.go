@@ -0,0 +1,162 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"semtag/pkg/conventionalCommit"
+	"semtag/pkg/remote"
+)
+
+const (
+	// IssueReferenceRegex matches the references to the issues (e.g. #123); it is linked if the issue URL is known
+	IssueReferenceRegex = `\B#(\d+)\b`
+	// MergeRequestReferenceRegex matches the references to the GitLab merge requests (e.g. !45); it is linked if the merge request URL is known
+	MergeRequestReferenceRegex = `\B!(\d+)\b`
+)
+
+var (
+	ErrParseReference = errors.New("reference pattern definition can't be parsed")
+
+	// closingFooterTokens are the footer tokens (in lower case) of the commits that close an issue (e.g. Closes #12)
+	closingFooterTokens = map[string]bool{
+		"close": true, "closes": true, "closed": true,
+		"fix": true, "fixes": true, "fixed": true,
+		"resolve": true, "resolves": true, "resolved": true,
+	}
+	// referenceFooterTokens are the footer tokens (in lower case) of the commits that refer to an issue without closing it (e.g. Refs: #12)
+	referenceFooterTokens = map[string]bool{
+		"ref": true, "refs": true, "references": true, "see": true,
+	}
+)
+
+// ReferencePattern matches the references to issues, merge requests or tickets in the commit messages (e.g. PROJ-987)
+type ReferencePattern struct {
+	// Regex of a reference; the id of the reference is the first capturing group, or the whole match if there is none
+	Regex *regexp.Regexp
+	// Url of a reference: a URL pattern with the placeholder {id} or a base URL to which the id is appended
+	Url string
+}
+
+/*
+ReferencePatterns is a list of reference patterns, from the highest to the lowest priority. It can be used as a repeatable command line flag with the format: regex=url
+  - the regex can't contain '='
+  - the url can contain the placeholder {id} (e.g. [A-Z]+-[0-9]+=https://jira.example.com/browse/{id})
+*/
+type ReferencePatterns []ReferencePattern
+
+func (ps ReferencePatterns) String() string {
+	var out []string
+	for _, p := range ps {
+		out = append(out, p.Regex.String()+"="+p.Url)
+	}
+	return strings.Join(out, " ")
+}
+
+func (ps *ReferencePatterns) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return fmt.Errorf("%v: %q: expected regex=url", ErrParseReference, value)
+	}
+	re, err := regexp.Compile(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return fmt.Errorf("%v: %q: %v", ErrParseReference, value, err)
+	}
+	*ps = append(*ps, ReferencePattern{Regex: re, Url: strings.TrimSpace(parts[1])})
+	return nil
+}
+
+// References configures the linking of the references in the commit messages
+type References struct {
+	// Patterns of the references, in addition to the issues (#123) and the GitLab merge requests (!45) whose URLs are known
+	Patterns ReferencePatterns
+	// Footers is true if the references in the footers of the commits (e.g. Closes #12, Refs: PROJ-987) are collected, in addition to the ones in the subject
+	Footers bool
+}
+
+// segment is a part of a text; it is a reference if it has a reference id
+type segment struct {
+	Text string
+	Id   string
+	Url  string
+}
+
+// referencePatterns returns the reference patterns of the links: the patterns of the references, then the issues and the merge requests if their URL is set
+func (l Links) referencePatterns() ReferencePatterns {
+	patterns := append(ReferencePatterns{}, l.References.Patterns...)
+	if l.IssueUrl != "" {
+		patterns = append(patterns, ReferencePattern{Regex: regexp.MustCompile(IssueReferenceRegex), Url: l.IssueUrl})
+	}
+	if l.MergeRequestUrl != "" {
+		patterns = append(patterns, ReferencePattern{Regex: regexp.MustCompile(MergeRequestReferenceRegex), Url: l.MergeRequestUrl})
+	}
+	return patterns
+}
+
+// split splits a text in segments; the references are linked, and a reference matched by a pattern can't be matched by a pattern with a lower priority
+func (ps ReferencePatterns) split(text string) []segment {
+	segments := []segment{{Text: text}}
+	for _, p := range ps {
+		var next []segment
+		for _, s := range segments {
+			if s.Id != "" {
+				next = append(next, s)
+				continue
+			}
+			next = append(next, p.split(s.Text)...)
+		}
+		segments = next
+	}
+	return segments
+}
+
+func (p ReferencePattern) split(text string) []segment {
+	var segments []segment
+	last := 0
+	for _, m := range p.Regex.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		id := text[m[0]:m[1]]
+		if len(m) >= 4 && m[2] >= 0 {
+			id = text[m[2]:m[3]]
+		}
+		if last < m[0] {
+			segments = append(segments, segment{Text: text[last:m[0]]})
+		}
+		segments = append(segments, segment{Text: text[m[0]:m[1]], Id: id, Url: expandUrl(p.Url, id, remote.PlaceholderId, id)})
+		last = m[1]
+	}
+	if last < len(text) {
+		segments = append(segments, segment{Text: text[last:]})
+	}
+	return segments
+}
+
+/*
+footerReferences returns the references in the footers of a commit message that close or refer to an issue
+  - the issue number of a footer with the GitHub syntax (e.g. Closes #12) is parsed without #, so it is restored
+  - Closed is true for the closing footers (e.g. Fixes, Resolves)
+*/
+func (ps ReferencePatterns) footerReferences(footers []conventionalCommit.Footer) []TemplateReference {
+	var refs []TemplateReference
+	for _, f := range footers {
+		token := strings.ToLower(f.Token)
+		closing := closingFooterTokens[token]
+		if !closing && !referenceFooterTokens[token] {
+			continue
+		}
+		value := f.Value
+		if value != "" && value[0] >= '0' && value[0] <= '9' {
+			value = "#" + value
+		}
+		for _, s := range ps.split(value) {
+			if s.Id != "" {
+				refs = append(refs, TemplateReference{Text: s.Text, Id: s.Id, Url: s.Url, Closed: closing})
+			}
+		}
+	}
+	return refs
+}
@@ -0,0 +1,95 @@
+package changelog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"semtag/pkg/conventionalCommit"
+)
+
+func Test_ReferencesInDescription(t *testing.T) {
+	// arrange
+	var patterns ReferencePatterns
+	if err := patterns.Set(`[A-Z]+-[0-9]+=https://jira.example.com/browse/{id}`); err != nil {
+		t.Fatal(err)
+	}
+	links := Links{
+		IssueUrl:        "https://gitlab.com/org/repo/-/issues",
+		MergeRequestUrl: "https://gitlab.com/org/repo/-/merge_requests/{id}",
+		References:      References{Patterns: patterns},
+	}
+	tables := []struct {
+		description string
+
+		want []TemplateSegment
+	}{
+		{"add the export", []TemplateSegment{{Text: "add the export"}}},
+		{"add the export (#12)", []TemplateSegment{{Text: "add the export ("}, {Text: "#12", Url: "https://gitlab.com/org/repo/-/issues/12"}, {Text: ")"}}},
+		{"PROJ-987 merge !45", []TemplateSegment{
+			{Text: "PROJ-987", Url: "https://jira.example.com/browse/PROJ-987"},
+			{Text: " merge "},
+			{Text: "!45", Url: "https://gitlab.com/org/repo/-/merge_requests/45"},
+		}},
+		{"see page#12 and issue#3", []TemplateSegment{{Text: "see page#12 and issue#3"}}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("description=%q", tb.description), func(t *testing.T) {
+			c := Commit{Message: conventionalCommit.Parse(tb.description, "")}
+			got := newTemplateCommit(c, links).DescriptionSegments
+
+			// assert
+			if !reflect.DeepEqual(got, tb.want) {
+				t.Errorf("got %+v want %+v", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_FooterReferences(t *testing.T) {
+	// arrange
+	links := Links{IssueUrl: "https://github.com/org/repo/issues/{id}", References: References{Footers: true}}
+	c := Commit{Message: conventionalCommit.Parse("fix: handle the timeout (#3)", "Closes #12, #13\nRefs: #3\nReviewed-by: #14")}
+	want := []TemplateReference{
+		{Text: "#3", Id: "3", Url: "https://github.com/org/repo/issues/3"},
+		{Text: "#12", Id: "12", Url: "https://github.com/org/repo/issues/12", Closed: true},
+		{Text: "#13", Id: "13", Url: "https://github.com/org/repo/issues/13", Closed: true},
+	}
+
+	// act
+	got := newTemplateCommit(c, links).References
+
+	// assert
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+}
+
+func Test_ReferencePatternsSet(t *testing.T) {
+	// arrange
+	tables := []struct {
+		value string
+
+		wantError bool
+	}{
+		{`[A-Z]+-[0-9]+=https://jira.example.com/browse/{id}`, false},
+		{`TICKET-([0-9]+)=https://tickets.example.com/?id={id}`, false},
+		{`[A-Z]+-[0-9]+`, true},
+		{`[A-Z+=https://jira.example.com/browse/{id}`, true},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("value=%q", tb.value), func(t *testing.T) {
+			var patterns ReferencePatterns
+			err := patterns.Set(tb.value)
+
+			// assert
+			if (err != nil) != tb.wantError {
+				t.Errorf("got error %v want error %v", err, tb.wantError)
+			}
+		})
+	}
+}
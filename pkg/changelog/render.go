@@ -0,0 +1,173 @@
+package changelog
+
+import (
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// releaseTemplateName is the name of the template that renders a single release; it is used to insert a release in incremental mode
+	releaseTemplateName = "release"
+	// headerTemplateName is the name of the template that renders the beginning of a new changelog in incremental mode
+	headerTemplateName = "header"
+)
+
+var (
+	//go:embed templates
+	builtinTemplates embed.FS
+
+	// templateFuncs are the functions available in the changelog templates
+	templateFuncs = template.FuncMap{
+		// date formats a time in UTC with a Go layout, e.g. {{ date "2006-01-02" .Date }}
+		"date": func(layout string, t time.Time) string {
+			return t.UTC().Format(layout)
+		},
+		// link returns a Markdown hyperlink, or the text if the URL is empty, e.g. {{ link .ShortHash .Url }}
+		"link": func(text, url string) string {
+			if url == "" {
+				return text
+			}
+			return fmt.Sprintf("[%s](%s)", text, url)
+		},
+		// lines splits a text in lines, e.g. {{ range lines .BreakingChange }}> {{ . }}{{ end }}
+		"lines": func(s string) []string {
+			return strings.Split(s, "\n")
+		},
+		"join":  strings.Join,
+		"trim":  strings.TrimSpace,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+)
+
+// Renderer writes a changelog in a specific format
+type Renderer interface {
+	Render(w io.Writer, cl Changelog) error
+}
+
+// ReleaseRenderer writes a single release of a changelog, e.g. the release notes of a version
+type ReleaseRenderer interface {
+	RenderRelease(w io.Writer, r Release) error
+}
+
+// IncrementalRenderer writes the releases inserted in an existing changelog
+type IncrementalRenderer interface {
+	ReleaseRenderer
+	// RenderHeader writes the beginning of a new changelog, which is followed by the insert marker
+	RenderHeader(w io.Writer) error
+}
+
+// Prepender inserts the releases in an existing changelog with the syntax of its format (e.g. at the top of debian/changelog), instead of the insert marker
+type Prepender interface {
+	ReleaseRenderer
+	// HasRelease checks if the changelog already contains the release of the tag
+	HasRelease(contents, tag string) bool
+	// Prepend inserts the release above the previous releases of the changelog
+	Prepend(contents string, r Release) (string, error)
+}
+
+// executor is implemented by both text/template and html/template
+type executor interface {
+	Execute(w io.Writer, data interface{}) error
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// Template renders the changelog with a text/template (or a html/template); the data model of the template is TemplateData
+type Template struct {
+	Template executor
+	Links    Links
+	// Sections of a release; the default sections are used if none is set
+	Sections Sections
+}
+
+func (t Template) Render(w io.Writer, cl Changelog) error {
+	return t.Template.Execute(w, NewTemplateData(cl, t.Links, t.Sections))
+}
+
+// RenderRelease renders the release with the template named "release" (the data model is TemplateRelease); if the template doesn't define it, the whole template is rendered with a changelog that contains only the release
+func (t Template) RenderRelease(w io.Writer, r Release) error {
+	if !t.defines(releaseTemplateName) {
+		return t.Render(w, Changelog{Releases: []Release{r}})
+	}
+	return t.Template.ExecuteTemplate(w, releaseTemplateName, newTemplateRelease(r, t.Links, t.Sections))
+}
+
+// RenderHeader renders the template named "header"; if the template doesn't define it, a Markdown title is written
+func (t Template) RenderHeader(w io.Writer) error {
+	if !t.defines(headerTemplateName) {
+		_, err := io.WriteString(w, newChangelogHeader)
+		return err
+	}
+	return t.Template.ExecuteTemplate(w, headerTemplateName, nil)
+}
+
+// defines checks if the template contains an associated template with the name
+func (t Template) defines(name string) bool {
+	switch tmpl := t.Template.(type) {
+	case *template.Template:
+		return tmpl.Lookup(name) != nil
+	case *htmlTemplate.Template:
+		return tmpl.Lookup(name) != nil
+	}
+	return false
+}
+
+// ParseTemplate parses a changelog template; the template functions (date, link, lines, join, trim, upper, lower) are available
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
+
+// LoadTemplate reads and parses a changelog template file
+func LoadTemplate(path string) (*template.Template, error) {
+	dat, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the changelog template %q: %v", path, err)
+	}
+	t, err := ParseTemplate(filepath.Base(path), string(dat))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the changelog template %q: %v", path, err)
+	}
+	return t, nil
+}
+
+// builtinTemplate parses a template of the templates directory; the HTML templates are parsed with html/template, so that the text of the commits is escaped
+func builtinTemplate(name string, html bool) executor {
+	text, err := builtinTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		panic(err)
+	}
+	if html {
+		return htmlTemplate.Must(htmlTemplate.New(name).Funcs(htmlTemplate.FuncMap(templateFuncs)).Parse(string(text)))
+	}
+	return template.Must(ParseTemplate(name, string(text)))
+}
+
+// Markdown renders the changelog as a Markdown document with the built-in template; the tags and the commits are hyperlinks if their base URLs are set
+type Markdown struct {
+	Links Links
+	// Sections of a release; the default sections are used if none is set
+	Sections Sections
+}
+
+func (m Markdown) Render(w io.Writer, cl Changelog) error {
+	return m.template().Render(w, cl)
+}
+
+func (m Markdown) RenderHeader(w io.Writer) error {
+	return m.template().RenderHeader(w)
+}
+
+func (m Markdown) RenderRelease(w io.Writer, r Release) error {
+	return m.template().RenderRelease(w, r)
+}
+
+func (m Markdown) template() Template {
+	return Template{Template: builtinTemplate("markdown.tmpl", false), Links: m.Links, Sections: m.Sections}
+}
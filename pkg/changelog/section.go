@@ -0,0 +1,127 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"semtag/pkg/conventionalCommit"
+)
+
+const (
+	// SectionTypeBreaking is the section type of the breaking changes; their BREAKING CHANGE text is quoted under the commit
+	SectionTypeBreaking = "breaking"
+	// SectionTypeOther is the section type of all the commits whose type isn't listed in another section
+	SectionTypeOther = "*"
+)
+
+var (
+	ErrParseSection = errors.New("changelog section definition can't be parsed")
+
+	// DefaultSections of a release, in the order in which they are rendered
+	DefaultSections = Sections{
+		{Title: "Breaking Changes", Types: []string{SectionTypeBreaking}},
+		{Title: "Features", Types: []string{conventionalCommit.TypeFeature}},
+		{Title: "Bug Fixes", Types: []string{conventionalCommit.TypeFix}},
+		{Title: "Performance", Types: []string{conventionalCommit.TypePerformance}},
+		{Title: "Other", Types: []string{SectionTypeOther}},
+	}
+)
+
+// Section of a release that contains the commits of the listed Conventional Commit types
+type Section struct {
+	Title string
+	Types []string
+}
+
+// Sections of a release. It can be used as a repeatable command line flag with the format: title=type[,type...]
+type Sections []Section
+
+func (ss Sections) String() string {
+	var out []string
+	for _, s := range ss {
+		out = append(out, s.Title+"="+strings.Join(s.Types, ","))
+	}
+	return strings.Join(out, " ")
+}
+
+func (ss *Sections) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("%v: %q: expected title=type[,type]", ErrParseSection, value)
+	}
+
+	s := Section{Title: strings.TrimSpace(parts[0])}
+	for _, t := range strings.Split(parts[1], ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			s.Types = append(s.Types, t)
+		}
+	}
+	if len(s.Types) == 0 {
+		return fmt.Errorf("%v: %q: no commit type", ErrParseSection, value)
+	}
+	*ss = append(*ss, s)
+	return nil
+}
+
+// OrDefault returns the sections, or the default sections if none is defined
+func (ss Sections) OrDefault() Sections {
+	if len(ss) == 0 {
+		return DefaultSections
+	}
+	return ss
+}
+
+// ReleaseSection is a section of a release and its commits
+type ReleaseSection struct {
+	Title string
+	// Breaking is true for the section of the breaking changes
+	Breaking bool
+	Commits  []Commit
+}
+
+/*
+Group the commits of a release in the sections; the sections without commits are skipped
+  - a breaking change is listed in the section of the breaking changes and in the section of its type
+  - a commit whose type isn't listed in any section goes to the section of the other commits; it is skipped if there is no such section
+*/
+func (ss Sections) Group(commits []Commit) []ReleaseSection {
+	grouped := make([][]Commit, len(ss))
+	for _, c := range commits {
+		other, found := -1, false
+		for i, s := range ss {
+			switch {
+			case s.hasType(SectionTypeBreaking) && c.Message.Breaking:
+				grouped[i] = append(grouped[i], c)
+				found = found || s.hasType(c.Message.Type)
+			case !found && s.hasType(c.Message.Type):
+				grouped[i] = append(grouped[i], c)
+				found = true
+			}
+			if other < 0 && s.hasType(SectionTypeOther) {
+				other = i
+			}
+		}
+		if !found && other >= 0 && !(c.Message.Breaking && ss[other].hasType(SectionTypeBreaking)) {
+			grouped[other] = append(grouped[other], c)
+		}
+	}
+
+	var out []ReleaseSection
+	for i, s := range ss {
+		if len(grouped[i]) == 0 {
+			continue
+		}
+		out = append(out, ReleaseSection{Title: s.Title, Breaking: s.hasType(SectionTypeBreaking), Commits: grouped[i]})
+	}
+	return out
+}
+
+func (s Section) hasType(t string) bool {
+	for _, st := range s.Types {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
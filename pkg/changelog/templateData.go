@@ -0,0 +1,245 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"semtag/pkg/remote"
+)
+
+/*
+Links contains the URLs of the hyperlinks; a link is empty if its URL isn't set. A URL is either:
+  - a base URL, to which the hash, the tag or the comparison is appended (e.g. https://github.com/org/repo/commit)
+  - a URL pattern with placeholders (e.g. https://bitbucket.org/org/repo/branches/compare/{tag}%0D{previous})
+*/
+type Links struct {
+	// CommitUrl is the URL of the commits, with the placeholder {hash}; the link of a base URL is <CommitUrl>/<hash>
+	CommitUrl string
+	// TagUrl is the URL of the tags, with the placeholder {tag}; the link of a base URL is <TagUrl>/<tag>
+	TagUrl string
+	// CompareUrl is the URL of the comparisons, with the placeholders {previous} and {tag}; the link of a base URL is <CompareUrl>/<previous tag>...<tag>
+	CompareUrl string
+	// IssueUrl is the URL of the issues, with the placeholder {id}; the link of a base URL is <IssueUrl>/<id>
+	IssueUrl string
+	// MergeRequestUrl is the URL of the GitLab merge requests, with the placeholder {id}; the link of a base URL is <MergeRequestUrl>/<id>
+	MergeRequestUrl string
+	// References configures the linking of the references to issues, merge requests and tickets in the commit messages
+	References References
+}
+
+func (l Links) commit(hash string) string {
+	return expandUrl(l.CommitUrl, hash, remote.PlaceholderHash, hash)
+}
+
+func (l Links) tag(tag string) string {
+	return expandUrl(l.TagUrl, tag, remote.PlaceholderTag, tag)
+}
+
+func (l Links) compare(previous, tag string) string {
+	if previous == "" {
+		return ""
+	}
+	return expandUrl(l.CompareUrl, previous+"..."+tag, remote.PlaceholderPrevious, previous, remote.PlaceholderTag, tag)
+}
+
+// expandUrl replaces the placeholders of a URL pattern; if the URL has no placeholder, it is a base URL and the path is appended to it. An empty URL gives an empty link
+func expandUrl(url, path string, oldNew ...string) string {
+	if url == "" {
+		return ""
+	}
+	if expanded := strings.NewReplacer(oldNew...).Replace(url); expanded != url {
+		return expanded
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(url, "/"), path)
+}
+
+// TemplateData is the data model of the changelog templates
+type TemplateData struct {
+	// Unreleased contains the commits since the latest release; its tag and date are empty
+	Unreleased TemplateRelease
+	// Releases from the newest to the oldest
+	Releases []TemplateRelease
+}
+
+// TemplateRelease is a release in the data model of the changelog templates
+type TemplateRelease struct {
+	Tag string
+	// PreviousTag is the tag of the previous release; it is empty for the oldest release
+	PreviousTag string
+	// Date of the tagged commit
+	Date time.Time
+	// Url of the tag
+	Url string
+	// CompareUrl is the URL of the comparison between the previous tag and the tag
+	CompareUrl string
+	// Sections contains the commits grouped by type; the sections without commits are skipped
+	Sections []TemplateSection
+	// Commits from the oldest to the newest
+	Commits []TemplateCommit
+	// Authors of the commits, in the order of their first commit
+	Authors []TemplateAuthor
+	// Contributors are the authors and the co-authors of the commits, deduplicated with the mailmap, in the order of their first commit
+	Contributors []TemplateContributor
+	// References of the commits to issues, merge requests and tickets, in the order of their first reference
+	References []TemplateReference
+}
+
+// TemplateSection is a section of a release in the data model of the changelog templates
+type TemplateSection struct {
+	Title string
+	// Breaking is true for the section of the breaking changes
+	Breaking bool
+	Commits  []TemplateCommit
+}
+
+// TemplateCommit is a commit in the data model of the changelog templates
+type TemplateCommit struct {
+	Hash      string
+	ShortHash string
+	Url       string
+	Date      time.Time
+	Author    TemplateAuthor
+	// CoAuthors are the co-authors of the Co-authored-by trailers
+	CoAuthors []TemplateAuthor
+	// Subject is the first line of the commit message
+	Subject string
+	// Type, Scope and Description are parsed from the subject (Conventional Commits); Type is empty and Description is the subject if the subject doesn't follow the specification
+	Type        string
+	Scope       string
+	Description string
+	// DescriptionSegments is the description split in text and references, so that the references can be linked
+	DescriptionSegments []TemplateSegment
+	// References to issues, merge requests and tickets in the subject (and in the footers if enabled)
+	References []TemplateReference
+	Body       string
+	Breaking   bool
+	// BreakingChange is the text of the BREAKING CHANGE footer
+	BreakingChange string
+}
+
+// TemplateSegment is a part of a text in the data model of the changelog templates; it is a reference if its Url is set
+type TemplateSegment struct {
+	Text string
+	Url  string
+}
+
+// TemplateReference is a reference to an issue, a merge request or a ticket in the data model of the changelog templates
+type TemplateReference struct {
+	// Text of the reference (e.g. #12, PROJ-987)
+	Text string
+	// Id of the reference (e.g. 12, PROJ-987)
+	Id  string
+	Url string
+	// Closed is true if a commit closes the issue with a footer (e.g. Closes #12)
+	Closed bool
+}
+
+// TemplateAuthor is a commit author in the data model of the changelog templates
+type TemplateAuthor struct {
+	Name  string
+	Email string
+}
+
+// TemplateContributor is a contributor of a release in the data model of the changelog templates
+type TemplateContributor struct {
+	Name  string
+	Email string
+	// FirstTime is true if it is the first contribution to the repository
+	FirstTime bool
+}
+
+// NewTemplateData creates the data model of the changelog templates
+func NewTemplateData(cl Changelog, links Links, sections Sections) TemplateData {
+	data := TemplateData{Unreleased: newTemplateRelease(cl.Unreleased, links, sections)}
+	for _, r := range cl.Releases {
+		data.Releases = append(data.Releases, newTemplateRelease(r, links, sections))
+	}
+	return data
+}
+
+// newTemplateRelease creates a release of the data model; the release without a tag is the unreleased one and it is compared with HEAD
+func newTemplateRelease(r Release, links Links, sections Sections) TemplateRelease {
+	tr := TemplateRelease{
+		Tag:         r.Tag,
+		PreviousTag: r.Previous,
+		Date:        r.Date,
+	}
+	if r.Tag != "" {
+		tr.Url = links.tag(r.Tag)
+		tr.CompareUrl = links.compare(r.Previous, r.Tag)
+	} else {
+		tr.CompareUrl = links.compare(r.Previous, "HEAD")
+	}
+
+	seen := map[string]bool{}
+	for _, c := range r.Commits {
+		tc := newTemplateCommit(c, links)
+		tr.Commits = append(tr.Commits, tc)
+		if !seen[tc.Author.Email] {
+			seen[tc.Author.Email] = true
+			tr.Authors = append(tr.Authors, tc.Author)
+		}
+		tr.References = appendReferences(tr.References, tc.References...)
+	}
+	for _, c := range r.Contributors {
+		tr.Contributors = append(tr.Contributors, TemplateContributor{Name: c.Name, Email: c.Email, FirstTime: c.FirstTime})
+	}
+	for _, s := range sections.OrDefault().Group(r.Commits) {
+		ts := TemplateSection{Title: s.Title, Breaking: s.Breaking}
+		for _, c := range s.Commits {
+			ts.Commits = append(ts.Commits, newTemplateCommit(c, links))
+		}
+		tr.Sections = append(tr.Sections, ts)
+	}
+	return tr
+}
+
+func newTemplateCommit(c Commit, links Links) TemplateCommit {
+	tc := TemplateCommit{
+		Hash:           c.Hash,
+		ShortHash:      c.ShortHash,
+		Url:            links.commit(c.Hash),
+		Date:           c.Date,
+		Author:         TemplateAuthor{Name: c.AuthorName, Email: c.AuthorEmail},
+		Subject:        c.Subject,
+		Type:           c.Message.Type,
+		Scope:          c.Message.Scope,
+		Description:    c.Message.Description,
+		Body:           c.Message.Body,
+		Breaking:       c.Message.Breaking,
+		BreakingChange: c.Message.BreakingChange,
+	}
+	for _, co := range c.CoAuthors {
+		tc.CoAuthors = append(tc.CoAuthors, TemplateAuthor{Name: co.Name, Email: co.Email})
+	}
+
+	patterns := links.referencePatterns()
+	for _, s := range patterns.split(c.Message.Description) {
+		tc.DescriptionSegments = append(tc.DescriptionSegments, TemplateSegment{Text: s.Text, Url: s.Url})
+		if s.Id != "" {
+			tc.References = appendReferences(tc.References, TemplateReference{Text: s.Text, Id: s.Id, Url: s.Url})
+		}
+	}
+	if links.References.Footers {
+		tc.References = appendReferences(tc.References, patterns.footerReferences(c.Message.Footers)...)
+	}
+	return tc
+}
+
+// appendReferences appends the references that aren't in the list yet; a reference is closed if any commit closes it
+func appendReferences(refs []TemplateReference, more ...TemplateReference) []TemplateReference {
+	for _, m := range more {
+		found := false
+		for i := range refs {
+			if refs[i].Text == m.Text {
+				refs[i].Closed = refs[i].Closed || m.Closed
+				found = true
+			}
+		}
+		if !found {
+			refs = append(refs, m)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,115 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// TagSeparator separates the component name from the version number in the git tags (e.g. shared-lib/v1.2.0)
+	TagSeparator = "/"
+)
+
+var (
+	ErrParseComponent       = errors.New("component definition can't be parsed")
+	ErrParseDependency      = errors.New("component dependency definition can't be parsed")
+	ErrDuplicateComponent   = errors.New("component is defined more than once")
+	ErrUnknownDependency    = errors.New("component depends on an unknown component")
+	ErrDependencyCycle      = errors.New("dependency cycle detected between components")
+	ErrComponentNotFound    = errors.New("component not found")
+	ErrSelfDependency       = errors.New("component can't depend on itself")
+	ErrDependencyScope      = errors.New("the dependency scope must increment the version")
+	errEmptyComponentValues = errors.New("empty name or path")
+)
+
+// Component is a part of a (mono)repository that is versioned and released independently
+type Component struct {
+	Name string
+	// Paths that contain the source of the component; a commit that changes any of them is relevant for the component
+	Paths []string
+	// Prefix for the git tags of the component (e.g. shared-lib/v)
+	Prefix string
+	// DependsOn contains the names of the components this component depends on
+	DependsOn []string
+	// InitialVersion is the version of the component if it has never been released; the default version is used if it is empty
+	InitialVersion string
+}
+
+// List of components. It can be used as a repeatable command line flag with the format: name=path[,path...]
+type List []Component
+
+func (l List) String() string {
+	var out []string
+	for _, c := range l {
+		out = append(out, c.Name+"="+strings.Join(c.Paths, ","))
+	}
+	return strings.Join(out, " ")
+}
+
+func (l *List) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return fmt.Errorf("%v: %q: %v", ErrParseComponent, value, errEmptyComponentValues)
+	}
+
+	c := Component{Name: strings.TrimSpace(parts[0])}
+	for _, p := range strings.Split(parts[1], ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			c.Paths = append(c.Paths, p)
+		}
+	}
+	*l = append(*l, c)
+	return nil
+}
+
+// WithPrefix sets the tag prefix of all the components that don't have one: <name>/<prefix> (e.g. shared-lib/v)
+func (l List) WithPrefix(prefix string) List {
+	var out List
+	for _, c := range l {
+		if c.Prefix == "" {
+			c.Prefix = c.Name + TagSeparator + prefix
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// WithDependencies adds the dependencies to the components
+func (l List) WithDependencies(deps Dependencies) List {
+	var out List
+	for _, c := range l {
+		c.DependsOn = append(c.DependsOn, deps[c.Name]...)
+		out = append(out, c)
+	}
+	return out
+}
+
+// Dependencies maps a component name to the names of its dependencies. It can be used as a repeatable command line flag with the format: name=dependency[,dependency...]
+type Dependencies map[string][]string
+
+func (d Dependencies) String() string {
+	var out []string
+	for name, deps := range d {
+		out = append(out, name+"="+strings.Join(deps, ","))
+	}
+	return strings.Join(out, " ")
+}
+
+func (d *Dependencies) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return fmt.Errorf("%v: %q: %v", ErrParseDependency, value, errEmptyComponentValues)
+	}
+	if *d == nil {
+		*d = Dependencies{}
+	}
+
+	name := strings.TrimSpace(parts[0])
+	for _, dep := range strings.Split(parts[1], ",") {
+		if dep = strings.TrimSpace(dep); dep != "" {
+			(*d)[name] = append((*d)[name], dep)
+		}
+	}
+	return nil
+}
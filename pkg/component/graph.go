@@ -0,0 +1,182 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/output"
+	"semtag/pkg/version"
+)
+
+// Graph of the components and of the dependencies between them
+type Graph struct {
+	// DependencyScope is the version scope incremented for a component when at least one of its dependencies is released
+	DependencyScope version.Scope
+
+	components map[string]Component
+	// names keeps the order in which the components were defined, so that the release plan is deterministic
+	names []string
+}
+
+// Release of a component as part of a release plan
+type Release struct {
+	Component Component
+	Scope     version.Scope
+	// TriggeredBy contains all the dependencies released in the same plan, in the order of DependsOn; the component is released at least with the DependencyScope because of them, even if it also has its own changes
+	TriggeredBy []string
+}
+
+// NewGraph validates the components and their dependencies and creates the dependency graph
+func NewGraph(components []Component, dependencyScope version.Scope) (*Graph, error) {
+	g := &Graph{
+		DependencyScope: dependencyScope,
+		components:      map[string]Component{},
+	}
+	for _, c := range components {
+		if _, ok := g.components[c.Name]; ok {
+			return nil, fmt.Errorf("%v: %q", ErrDuplicateComponent, c.Name)
+		}
+		g.components[c.Name] = c
+		g.names = append(g.names, c.Name)
+	}
+
+	for _, c := range components {
+		for _, dep := range c.DependsOn {
+			if dep == c.Name {
+				return nil, fmt.Errorf("%v: %q", ErrSelfDependency, c.Name)
+			}
+			if _, ok := g.components[dep]; !ok {
+				return nil, fmt.Errorf("%v: component=%q, dependency=%q", ErrUnknownDependency, c.Name, dep)
+			}
+		}
+	}
+
+	if _, err := g.Sort(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ParseDependencyScope parses the version scope incremented for a component when one of its dependencies is released; auto and none are refused, since the dependents would never be released
+func ParseDependencyScope(scopeAsString string) (version.Scope, error) {
+	s := version.Scope{}
+	if err := s.Parse(scopeAsString); err != nil {
+		return s, err
+	}
+	if s.Id != version.MAJOR && s.Id != version.MINOR && s.Id != version.PATCH {
+		return s, fmt.Errorf("%v: %q, scopes=[major minor patch]", ErrDependencyScope, scopeAsString)
+	}
+	return s, nil
+}
+
+// Get a component by its name
+func (g *Graph) Get(name string) (Component, error) {
+	c, ok := g.components[name]
+	if !ok {
+		return Component{}, fmt.Errorf("%v: %q", ErrComponentNotFound, name)
+	}
+	return c, nil
+}
+
+// Sort the components topologically: every component is placed after all of its dependencies
+func (g *Graph) Sort() ([]Component, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var sorted []Component
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%v: %s", ErrDependencyCycle, strings.Join(cyclePath(path, name), " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range g.components[name].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+
+		sorted = append(sorted, g.components[name])
+		return nil
+	}
+
+	for _, name := range g.names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+/*
+Plan calculates the topologically ordered list of releases based on the scopes of the changes made directly to each component:
+  - a component is released with its own scope if it has relevant changes
+  - a component is also released if at least one of its dependencies is released; the greater scope between its own scope and the DependencyScope is used
+  - components that don't need a release are not included in the plan
+*/
+func (g *Graph) Plan(directScopes map[string]version.Scope) ([]Release, error) {
+	sorted, err := g.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	released := map[string]bool{}
+	var plan []Release
+	for _, c := range sorted {
+		r := Release{
+			Component: c,
+			Scope:     version.Scope{Id: version.NONE},
+		}
+		if s, ok := directScopes[c.Name]; ok {
+			r.Scope = s
+		}
+
+		for _, dep := range c.DependsOn {
+			if !released[dep] {
+				continue
+			}
+			r.TriggeredBy = append(r.TriggeredBy, dep)
+			if g.DependencyScope.IsGreaterThan(r.Scope) {
+				r.Scope = g.DependencyScope
+			}
+		}
+
+		if r.Scope.Id == version.NONE || r.Scope.Id == version.AUTO {
+			continue
+		}
+		released[c.Name] = true
+		plan = append(plan, r)
+
+		output.Logger().WithFields(logrus.Fields{
+			"component":            c.Name,
+			"componentScope":       r.Scope.String(),
+			"componentTriggeredBy": r.TriggeredBy,
+		}).Debug("component added to the release plan")
+	}
+	return plan, nil
+}
+
+// cyclePath returns the part of the visited path that forms a cycle ending in the provided name (e.g. [a b c] + b -> [b c b])
+func cyclePath(path []string, name string) []string {
+	for i, p := range path {
+		if p == name {
+			cycle := append([]string{}, path[i:]...)
+			return append(cycle, name)
+		}
+	}
+	return append(path, name)
+}
@@ -0,0 +1,180 @@
+package component
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"semtag/pkg/version"
+)
+
+func Test_Plan(t *testing.T) {
+	// arrange
+	components := []Component{
+		{Name: "gateway", DependsOn: []string{"api"}},
+		{Name: "api", DependsOn: []string{"shared-lib"}},
+		{Name: "worker", DependsOn: []string{"shared-lib"}},
+		{Name: "shared-lib"},
+		{Name: "docs"},
+	}
+	tables := []struct {
+		directScopes    map[string]version.Scope
+		dependencyScope version.Scope
+
+		want []string
+	}{
+		{
+			map[string]version.Scope{"shared-lib": {Id: version.MINOR}},
+			version.Scope{Id: version.PATCH},
+			[]string{"shared-lib=minor", "api=patch<-shared-lib", "gateway=patch<-api", "worker=patch<-shared-lib"},
+		},
+		{
+			map[string]version.Scope{"shared-lib": {Id: version.MAJOR}, "api": {Id: version.PATCH}},
+			version.Scope{Id: version.MINOR},
+			[]string{"shared-lib=major", "api=minor<-shared-lib", "gateway=minor<-api", "worker=minor<-shared-lib"},
+		},
+		{
+			map[string]version.Scope{"api": {Id: version.MAJOR}, "docs": {Id: version.PATCH}},
+			version.Scope{Id: version.PATCH},
+			[]string{"api=major", "gateway=patch<-api", "docs=patch"},
+		},
+		{
+			// a released dependency is listed even if the component has a greater scope of its own
+			map[string]version.Scope{"shared-lib": {Id: version.PATCH}, "worker": {Id: version.MAJOR}},
+			version.Scope{Id: version.PATCH},
+			[]string{"shared-lib=patch", "api=patch<-shared-lib", "gateway=patch<-api", "worker=major<-shared-lib"},
+		},
+		{
+			map[string]version.Scope{"shared-lib": {Id: version.NONE}},
+			version.Scope{Id: version.PATCH},
+			nil,
+		},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("directScopes=%v, dependencyScope=%s", tb.directScopes, tb.dependencyScope), func(t *testing.T) {
+			g, err := NewGraph(components, tb.dependencyScope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan, err := g.Plan(tb.directScopes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// assert
+			var got []string
+			for _, r := range plan {
+				release := r.Component.Name + "=" + r.Scope.String()
+				if len(r.TriggeredBy) > 0 {
+					release += "<-" + strings.Join(r.TriggeredBy, ",")
+				}
+				got = append(got, release)
+			}
+			if !reflect.DeepEqual(got, tb.want) {
+				t.Errorf("got %v want %v", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_NewGraphNegative(t *testing.T) {
+	// arrange
+	tables := []struct {
+		components []Component
+
+		want string
+	}{
+		{[]Component{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"c"}}, {Name: "c", DependsOn: []string{"b"}}}, "b -> c -> b"},
+		{[]Component{{Name: "a", DependsOn: []string{"a"}}}, ErrSelfDependency.Error()},
+		{[]Component{{Name: "a", DependsOn: []string{"z"}}}, ErrUnknownDependency.Error()},
+		{[]Component{{Name: "a"}, {Name: "a"}}, ErrDuplicateComponent.Error()},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("want=%q", tb.want), func(t *testing.T) {
+			_, err := NewGraph(tb.components, version.Scope{Id: version.PATCH})
+
+			// assert
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tb.want)
+			}
+			if !strings.Contains(err.Error(), tb.want) {
+				t.Errorf("got %q want %q", err.Error(), tb.want)
+			}
+		})
+	}
+}
+
+func Test_ParseDependencyScope(t *testing.T) {
+	// arrange
+	tables := []struct {
+		scope string
+
+		want      version.Scope
+		wantError error
+	}{
+		{"major", version.Scope{Id: version.MAJOR}, nil},
+		{"minor", version.Scope{Id: version.MINOR}, nil},
+		{"PATCH", version.Scope{Id: version.PATCH}, nil},
+		{"auto", version.Scope{}, ErrDependencyScope},
+		{"none", version.Scope{}, ErrDependencyScope},
+		{"", version.Scope{}, ErrDependencyScope},
+		{"big", version.Scope{}, version.ErrParseScopeName},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("scope=%q", tb.scope), func(t *testing.T) {
+			got, err := ParseDependencyScope(tb.scope)
+
+			// assert
+			if tb.wantError != nil {
+				if err == nil || !strings.Contains(err.Error(), tb.wantError.Error()) {
+					t.Errorf("got %v want %v", err, tb.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tb.want {
+				t.Errorf("got %v want %v", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_ListSet(t *testing.T) {
+	// arrange
+	tables := []struct {
+		value string
+
+		want      Component
+		wantError bool
+	}{
+		{"api=services/api", Component{Name: "api", Paths: []string{"services/api"}}, false},
+		{"api=services/api, lib/api-client", Component{Name: "api", Paths: []string{"services/api", "lib/api-client"}}, false},
+		{"api", Component{}, true},
+		{"=services/api", Component{}, true},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("value=%q", tb.value), func(t *testing.T) {
+			var l List
+			err := l.Set(tb.value)
+
+			// assert
+			if (err != nil) != tb.wantError {
+				t.Fatalf("got error %v want error %t", err, tb.wantError)
+			}
+			if !tb.wantError && !reflect.DeepEqual(l[0], tb.want) {
+				t.Errorf("got %#v want %#v", l[0], tb.want)
+			}
+		})
+	}
+}
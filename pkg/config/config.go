@@ -0,0 +1,424 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"semtag/pkg/changelog"
+	"semtag/pkg/component"
+	"semtag/pkg/remote"
+	"semtag/pkg/version"
+	"semtag/pkg/versionControl"
+)
+
+const (
+	// DefaultFile is the name of the configuration file discovered at the root of the repository
+	DefaultFile = ".semtag.yaml"
+
+	// SchemeSemver is the only supported version scheme: Semantic Versioning
+	SchemeSemver = "semver"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid configuration file")
+
+	errUnknownScheme     = errors.New("unknown version scheme")
+	errEmptyValue        = errors.New("empty value")
+	errDuplicateValue    = errors.New("value defined more than once")
+	errUnknownDependency = errors.New("unknown component")
+
+	// lineRegex matches the line of the errors of the YAML decoder (e.g. line 3: cannot unmarshal !!seq into string)
+	lineRegex = regexp.MustCompile(`^line (\d+): (.*)$`)
+	// unknownFieldRegex matches the unknown keys reported by the YAML decoder (e.g. field incrment not found in type config.Commits)
+	unknownFieldRegex = regexp.MustCompile(`field (\S+) not found in type \S+`)
+)
+
+var GitRepo versionControl.VersionControl = &versionControl.GitRepository{}
+
+/*
+Config is the repository configuration file; every setting has the same meaning (and the same value format) as the command line flag it replaces, e.g.:
+
+	prefix: v
+	commits:
+	  increment: auto
+	files:
+	  - package.json
+	  - Chart.yaml:.appVersion
+	changelog:
+	  enabled: true
+	  format: keep-a-changelog
+	components:
+	  - name: api
+	    paths: [services/api]
+	    dependsOn: [shared-lib]
+*/
+type Config struct {
+	Prefix string `yaml:"prefix"`
+	Suffix string `yaml:"suffix"`
+	// Scheme of the version numbers; only semver is supported
+	Scheme string `yaml:"scheme"`
+	// Paths in which a change is required to create a git tag
+	Paths   []string `yaml:"paths"`
+	Commits Commits  `yaml:"commits"`
+	// Files that contain the version number, with the format of the -bump-file flag (e.g. Chart.yaml:.appVersion)
+	Files      []string    `yaml:"files"`
+	Changelog  Changelog   `yaml:"changelog"`
+	Components []Component `yaml:"components"`
+	Hooks      Hooks       `yaml:"hooks"`
+
+	path string
+	root *yaml.Node
+}
+
+// Commits contains the rules applied to the commit messages
+type Commits struct {
+	// Increment is the version scope to increment: none, auto, major, minor or patch
+	Increment string `yaml:"increment"`
+	// Types are the allowed Conventional Commit types, checked by the lint command
+	Types []string `yaml:"types"`
+	// DependencyIncrement is the version scope to increment for a component when one of its dependencies is released
+	DependencyIncrement string `yaml:"dependencyIncrement"`
+}
+
+// Changelog contains the settings of the changelog and of the release notes
+type Changelog struct {
+	// Enabled generates the changelog on every release
+	Enabled          *bool       `yaml:"enabled"`
+	Format           string      `yaml:"format"`
+	Template         string      `yaml:"template"`
+	File             string      `yaml:"file"`
+	Regex            string      `yaml:"regex"`
+	Incremental      *bool       `yaml:"incremental"`
+	Sections         []Section   `yaml:"sections"`
+	References       []Reference `yaml:"references"`
+	FooterReferences *bool       `yaml:"footerReferences"`
+	Emails           *bool       `yaml:"emails"`
+	HostType         string      `yaml:"hostType"`
+	HostUrl          string      `yaml:"hostUrl"`
+	Package          Package     `yaml:"package"`
+}
+
+// Section of a release in the changelog, with the commits of the listed types
+type Section struct {
+	Title string   `yaml:"title"`
+	Types []string `yaml:"types"`
+}
+
+// Reference links the references to tickets: the id matched by the regex replaces {id} in the url
+type Reference struct {
+	Pattern string `yaml:"pattern"`
+	Url     string `yaml:"url"`
+}
+
+// Package contains the metadata of the Debian and RPM changelogs
+type Package struct {
+	Name         string `yaml:"name"`
+	Maintainer   string `yaml:"maintainer"`
+	Distribution string `yaml:"distribution"`
+	Urgency      string `yaml:"urgency"`
+	Release      string `yaml:"release"`
+}
+
+// Component of a monorepo, versioned independently
+type Component struct {
+	Name      string   `yaml:"name"`
+	Paths     []string `yaml:"paths"`
+	DependsOn []string `yaml:"dependsOn"`
+}
+
+// Hooks are the commands executed during a release
+type Hooks struct {
+	// Command is executed for all the version tags, with %s as a placeholder for the version number (see the -command flag)
+	Command string `yaml:"command"`
+}
+
+// Setting is a value of the configuration file, with the format of the command line flag it replaces; a list has one setting per item
+type Setting struct {
+	// Key of the setting (e.g. changelog.format)
+	Key   string
+	Value string
+	// Line of the value in the configuration file
+	Line int
+}
+
+/*
+Find returns the path of the configuration file
+  - the provided path is returned if it is set; it must exist
+  - otherwise the default file at the root of the repository is returned, or an empty path if it doesn't exist
+*/
+func Find(path string) (string, error) {
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("%v: %v", ErrInvalidConfig, err)
+		}
+		return path, nil
+	}
+	root, err := GitRepo.GetRootDir()
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Join(root, DefaultFile)
+	if _, err := os.Stat(path); err != nil {
+		return "", nil
+	}
+	return path, nil
+}
+
+/*
+Load reads and validates a configuration file
+  - the unknown keys and the values of the wrong type are rejected
+  - the values are validated like the values of the command line flags
+  - the errors contain the line of the invalid value
+*/
+func Load(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("%v: %v", ErrInvalidConfig, err)
+	}
+
+	c := Config{path: path}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && err != io.EOF {
+		return Config{}, decodeError(path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Config{}, fmt.Errorf("%v: %s: %v", ErrInvalidConfig, path, err)
+	}
+	if len(doc.Content) > 0 {
+		c.root = doc.Content[0]
+	}
+	return c, c.validate()
+}
+
+// Path returns the path of the configuration file
+func (c Config) Path() string {
+	return c.path
+}
+
+// validate checks the values that the YAML schema can't check
+func (c Config) validate() error {
+	if c.Scheme != "" && c.Scheme != SchemeSemver {
+		return c.errorf("scheme", fmt.Errorf("%v: %q, schemes=[%s]", errUnknownScheme, c.Scheme, SchemeSemver))
+	}
+	if err := (&version.Scope{}).Parse(c.Commits.Increment); err != nil {
+		return c.errorf("commits.increment", err)
+	}
+	if c.Commits.DependencyIncrement != "" {
+		if _, err := component.ParseDependencyScope(c.Commits.DependencyIncrement); err != nil {
+			return c.errorf("commits.dependencyIncrement", err)
+		}
+	}
+	for i, t := range c.Commits.Types {
+		if strings.TrimSpace(t) == "" {
+			return c.errorf(index("commits.types", i), errEmptyValue)
+		}
+	}
+	for i, f := range c.Files {
+		if _, err := version.ParseFileSpec(f); err != nil {
+			return c.errorf(index("files", i), err)
+		}
+	}
+	if err := c.validateChangelog(); err != nil {
+		return err
+	}
+	return c.validateComponents()
+}
+
+func (c Config) validateChangelog() error {
+	cl := c.Changelog
+	if cl.Format != "" {
+		if _, err := changelog.FindFormat(cl.Format); err != nil {
+			return c.errorf("changelog.format", err)
+		}
+	}
+	if cl.Template != "" && cl.Format != "" {
+		return c.errorf("changelog.template", errors.New("the template and the format can't be used together"))
+	}
+	if cl.HostType != "" {
+		if err := remote.ValidateHostType(cl.HostType); err != nil {
+			return c.errorf("changelog.hostType", err)
+		}
+	}
+	for i, s := range cl.Sections {
+		var sections changelog.Sections
+		if err := sections.Set(s.Title + "=" + strings.Join(s.Types, ",")); err != nil {
+			return c.errorf(index("changelog.sections", i), err)
+		}
+	}
+	for i, r := range cl.References {
+		var patterns changelog.ReferencePatterns
+		if err := patterns.Set(r.Pattern + "=" + r.Url); err != nil {
+			return c.errorf(index("changelog.references", i), err)
+		}
+	}
+	return nil
+}
+
+func (c Config) validateComponents() error {
+	names := map[string]bool{}
+	for i, comp := range c.Components {
+		var list component.List
+		if err := list.Set(comp.Name + "=" + strings.Join(comp.Paths, ",")); err != nil {
+			return c.errorf(index("components", i), err)
+		}
+		if names[comp.Name] {
+			return c.errorf(index("components", i)+".name", fmt.Errorf("%v: %q", errDuplicateValue, comp.Name))
+		}
+		names[comp.Name] = true
+	}
+	for i, comp := range c.Components {
+		for j, dep := range comp.DependsOn {
+			if !names[dep] {
+				return c.errorf(index(index("components", i)+".dependsOn", j), fmt.Errorf("%v: %q", errUnknownDependency, dep))
+			}
+		}
+	}
+	return nil
+}
+
+/*
+Settings returns the values of the configuration file with the format of the command line flags, in the order of the file schema
+  - only the values that are set are returned
+  - a list (e.g. files) has one setting per item, except commits.types, which is a comma separated list
+  - changelog.enabled comes before the other changelog settings, which are applied only if the changelog is enabled
+*/
+func (c Config) Settings() []Setting {
+	var out []Setting
+	add := func(key, value string) {
+		if value != "" {
+			out = append(out, Setting{Key: key, Value: value, Line: c.line(key)})
+		}
+	}
+	addBool := func(key string, value *bool) {
+		if value != nil {
+			add(key, strconv.FormatBool(*value))
+		}
+	}
+	addItem := func(key, itemKey, value string) {
+		out = append(out, Setting{Key: key, Value: value, Line: c.line(itemKey)})
+	}
+
+	add("prefix", c.Prefix)
+	add("suffix", c.Suffix)
+	for i, p := range c.Paths {
+		addItem("paths", index("paths", i), p)
+	}
+	add("commits.increment", c.Commits.Increment)
+	add("commits.types", strings.Join(c.Commits.Types, ","))
+	add("commits.dependencyIncrement", c.Commits.DependencyIncrement)
+	for i, f := range c.Files {
+		addItem("files", index("files", i), f)
+	}
+
+	cl := c.Changelog
+	addBool("changelog.enabled", cl.Enabled)
+	add("changelog.format", cl.Format)
+	add("changelog.template", cl.Template)
+	add("changelog.file", cl.File)
+	add("changelog.regex", cl.Regex)
+	addBool("changelog.incremental", cl.Incremental)
+	for i, s := range cl.Sections {
+		addItem("changelog.sections", index("changelog.sections", i), s.Title+"="+strings.Join(s.Types, ","))
+	}
+	for i, r := range cl.References {
+		addItem("changelog.references", index("changelog.references", i), r.Pattern+"="+r.Url)
+	}
+	addBool("changelog.footerReferences", cl.FooterReferences)
+	addBool("changelog.emails", cl.Emails)
+	add("changelog.hostType", cl.HostType)
+	add("changelog.hostUrl", cl.HostUrl)
+	add("changelog.package.name", cl.Package.Name)
+	add("changelog.package.maintainer", cl.Package.Maintainer)
+	add("changelog.package.distribution", cl.Package.Distribution)
+	add("changelog.package.urgency", cl.Package.Urgency)
+	add("changelog.package.release", cl.Package.Release)
+
+	for i, comp := range c.Components {
+		addItem("components", index("components", i), comp.Name+"="+strings.Join(comp.Paths, ","))
+	}
+	for i, comp := range c.Components {
+		if len(comp.DependsOn) > 0 {
+			addItem("components.dependsOn", index("components", i)+".dependsOn", comp.Name+"="+strings.Join(comp.DependsOn, ","))
+		}
+	}
+	add("hooks.command", c.Hooks.Command)
+	return out
+}
+
+// decodeError returns the errors of the YAML decoder with the format of the validation errors: file:line: message
+func decodeError(path string, err error) error {
+	messages := []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+	var out []string
+	for _, m := range messages {
+		m = unknownFieldRegex.ReplaceAllString(m, "unknown key $1")
+		if match := lineRegex.FindStringSubmatch(m); match != nil {
+			m = fmt.Sprintf("%s:%s: %s", path, match[1], match[2])
+		} else {
+			m = fmt.Sprintf("%s: %s", path, m)
+		}
+		out = append(out, m)
+	}
+	return fmt.Errorf("%v: %s", ErrInvalidConfig, strings.Join(out, "; "))
+}
+
+// errorf returns the error of an invalid value, with its key and its line
+func (c Config) errorf(key string, err error) error {
+	if line := c.line(key); line > 0 {
+		return fmt.Errorf("%v: %s:%d: %s: %v", ErrInvalidConfig, c.path, line, key, err)
+	}
+	return fmt.Errorf("%v: %s: %s: %v", ErrInvalidConfig, c.path, key, err)
+}
+
+// line returns the line of the value of a key (e.g. changelog.sections.1.title), or 0 if the key isn't found
+func (c Config) line(key string) int {
+	node := c.root
+	for _, p := range strings.Split(key, ".") {
+		if node == nil {
+			return 0
+		}
+		node = child(node, p)
+	}
+	if node == nil {
+		return 0
+	}
+	return node.Line
+}
+
+// child returns the value of a mapping key or of a sequence index
+func child(node *yaml.Node, key string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// index returns the key of an item of a list (e.g. files.0)
+func index(key string, i int) string {
+	return fmt.Sprintf("%s.%d", key, i)
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"semtag/pkg/component"
+)
+
+func Test_LoadSettings(t *testing.T) {
+	// arrange
+	path := writeConfig(t, `prefix: v
+scheme: semver
+commits:
+  increment: auto
+  types: [feat, fix]
+files:
+  - package.json
+  - Chart.yaml:.appVersion
+changelog:
+  enabled: true
+  format: keep-a-changelog
+  sections:
+    - title: New Features
+      types: [feat]
+components:
+  - name: shared-lib
+    paths: [lib/shared]
+  - name: api
+    paths: [services/api, proto/api]
+    dependsOn: [shared-lib]
+hooks:
+  command: echo %s
+`)
+	want := []Setting{
+		{Key: "prefix", Value: "v", Line: 1},
+		{Key: "commits.increment", Value: "auto", Line: 4},
+		{Key: "commits.types", Value: "feat,fix", Line: 5},
+		{Key: "files", Value: "package.json", Line: 7},
+		{Key: "files", Value: "Chart.yaml:.appVersion", Line: 8},
+		{Key: "changelog.enabled", Value: "true", Line: 10},
+		{Key: "changelog.format", Value: "keep-a-changelog", Line: 11},
+		{Key: "changelog.sections", Value: "New Features=feat", Line: 13},
+		{Key: "components", Value: "shared-lib=lib/shared", Line: 16},
+		{Key: "components", Value: "api=services/api,proto/api", Line: 18},
+		{Key: "components.dependsOn", Value: "api=shared-lib", Line: 20},
+		{Key: "hooks.command", Value: "echo %s", Line: 22},
+	}
+
+	// act
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := c.Settings()
+
+	// assert
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func Test_LoadInvalid(t *testing.T) {
+	// arrange
+	tables := []struct {
+		contents string
+
+		want string
+	}{
+		{"prefix: v\ncommits:\n  incrment: auto\n", ":3: unknown key incrment"},
+		{"prefix: [v]\n", ":1: cannot unmarshal"},
+		{"prefix: v\n  bad: [\n", ":2: "},
+		{"scheme: calver\n", ":1: scheme: " + errUnknownScheme.Error()},
+		{"commits:\n  increment: big\n", ":2: commits.increment: "},
+		{"commits:\n  dependencyIncrement: auto\n", ":2: commits.dependencyIncrement: " + component.ErrDependencyScope.Error()},
+		{"commits:\n  dependencyIncrement: none\n", ":2: commits.dependencyIncrement: " + component.ErrDependencyScope.Error()},
+		{"files:\n  - package.json\n  - notes.txt\n", ":3: files.1: "},
+		{"changelog:\n  format: docx\n", ":2: changelog.format: "},
+		{"changelog:\n  sections:\n    - title: Fixes\n", ":3: changelog.sections.0: "},
+		{"components:\n  - name: api\n    paths: [api]\n  - name: api\n    paths: [v2]\n", ":4: components.1.name: " + errDuplicateValue.Error()},
+		{"components:\n  - name: api\n    paths: [api]\n    dependsOn: [lib]\n", ":4: components.0.dependsOn.0: " + errUnknownDependency.Error()},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("contents=%q", tb.contents), func(t *testing.T) {
+			_, err := Load(writeConfig(t, tb.contents))
+
+			// assert
+			if err == nil || !strings.Contains(err.Error(), ErrInvalidConfig.Error()) || !strings.Contains(err.Error(), tb.want) {
+				t.Errorf("got %v want %v with %q", err, ErrInvalidConfig, tb.want)
+			}
+		})
+	}
+}
+
+func Test_LoadEmpty(t *testing.T) {
+	// arrange
+	path := writeConfig(t, "# no settings\n")
+
+	// act
+	c, err := Load(path)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Settings(); len(got) != 0 {
+		t.Errorf("got %+v want no settings", got)
+	}
+}
+
+// writeConfig writes a configuration file in a temporary directory
+func writeConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), DefaultFile)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,83 @@
+package conventionalCommit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidHeader       = errors.New("the subject doesn't follow the format <type>[(<scope>)][!]: <description>")
+	ErrUnknownType         = errors.New("unknown commit type")
+	ErrEmptyScope          = errors.New("empty commit scope")
+	ErrEmptyBreakingChange = errors.New("empty BREAKING CHANGE footer")
+
+	// DefaultTypes are the usual commit types (the types of the Angular convention)
+	DefaultTypes = []string{"build", "chore", "ci", "docs", TypeFeature, TypeFix, TypePerformance, "refactor", "revert", "style", "test"}
+
+	// scissorsLine is the line of the commit message template of git commit --verbose below which the diff is displayed; the lines below it are not part of the message
+	scissorsLine = "# ------------------------ >8 ------------------------"
+
+	// autosquashPrefixes are the prefixes of the commits created by git commit --fixup or --squash; they are squashed before the merge, so they aren't linted
+	autosquashPrefixes = []string{"fixup! ", "squash! ", "amend! "}
+)
+
+/*
+Lint checks a commit message against the Conventional Commits specification and returns the violations
+  - the type must be one of the types (case insensitive)
+  - the scope can't be empty if it is set (e.g. feat(): ...)
+  - the BREAKING CHANGE footers must have a text
+  - the fixup and squash commits are skipped
+*/
+func Lint(subject, body string, types []string) []error {
+	subject = strings.TrimSpace(subject)
+	for _, p := range autosquashPrefixes {
+		if strings.HasPrefix(subject, p) {
+			return nil
+		}
+	}
+
+	match := headerRegex.FindStringSubmatch(subject)
+	if match == nil {
+		return []error{fmt.Errorf("%v: subject=%q", ErrInvalidHeader, subject)}
+	}
+	var errs []error
+	m := Parse(subject, body)
+	known := false
+	for _, t := range types {
+		known = known || strings.ToLower(t) == m.Type
+	}
+	if !known {
+		errs = append(errs, fmt.Errorf("%v: type=%q, types=%q", ErrUnknownType, m.Type, types))
+	}
+	if strings.HasPrefix(subject[len(match[1]):], "(") && strings.TrimSpace(match[2]) == "" {
+		errs = append(errs, fmt.Errorf("%v: subject=%q", ErrEmptyScope, subject))
+	}
+	for _, f := range m.Footers {
+		if f.Token == footerBreakingChange && f.Value == "" {
+			errs = append(errs, ErrEmptyBreakingChange)
+		}
+	}
+	return errs
+}
+
+/*
+SplitMessage splits a raw commit message (e.g. the file of a commit-msg hook) into its subject and its body
+  - the comment lines (#) and the lines below the scissors line of git commit --verbose are removed
+  - the leading blank lines are skipped and the body is separated from the subject by the first line break
+*/
+func SplitMessage(message string) (subject, body string) {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if line == scissorsLine {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	text := strings.TrimLeft(strings.Join(lines, "\n"), "\n")
+	parts := append(strings.SplitN(text, "\n", 2), "")
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
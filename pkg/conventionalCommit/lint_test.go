@@ -0,0 +1,71 @@
+package conventionalCommit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_Lint(t *testing.T) {
+	// arrange
+	tables := []struct {
+		subject string
+		body    string
+
+		want []error
+	}{
+		{"feat: add the export", "", nil},
+		{"Fix(api)!: handle errors", "BREAKING CHANGE: the errors are returned", nil},
+		{"fixup! feat: add the export", "", nil},
+		{"Update README.md", "", []error{ErrInvalidHeader}},
+		{"feat:add the export", "", []error{ErrInvalidHeader}},
+		{"feature: add the export", "", []error{ErrUnknownType}},
+		{"feat(): add the export", "", []error{ErrEmptyScope}},
+		{"wip( ): add the export", "", []error{ErrUnknownType, ErrEmptyScope}},
+		{"feat: add the export", "BREAKING CHANGE: \nRefs: #12", []error{ErrEmptyBreakingChange}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("subject=%q, body=%q", tb.subject, tb.body), func(t *testing.T) {
+			got := Lint(tb.subject, tb.body, DefaultTypes)
+
+			// assert
+			if len(got) != len(tb.want) {
+				t.Fatalf("got %v want %v", got, tb.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i].Error(), tb.want[i].Error()) {
+					t.Errorf("got %v want %v", got[i], tb.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_SplitMessage(t *testing.T) {
+	// arrange
+	tables := []struct {
+		message string
+
+		wantSubject string
+		wantBody    string
+	}{
+		{"feat: add the export\n", "feat: add the export", ""},
+		{"\nfeat: add the export\n\nthe body\n\nRefs: #12\n", "feat: add the export", "the body\n\nRefs: #12"},
+		{"# a comment\nfix: handle errors\n# Please enter the commit message\n", "fix: handle errors", ""},
+		{"fix: handle errors\n\nthe body\n" + scissorsLine + "\ndiff --git a/main.go b/main.go\n", "fix: handle errors", "the body"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("message=%q", tb.message), func(t *testing.T) {
+			subject, body := SplitMessage(tb.message)
+
+			// assert
+			if subject != tb.wantSubject || body != tb.wantBody {
+				t.Errorf("got %q, %q want %q, %q", subject, body, tb.wantSubject, tb.wantBody)
+			}
+		})
+	}
+}
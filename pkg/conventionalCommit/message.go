@@ -0,0 +1,99 @@
+package conventionalCommit
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	TypeFeature     = "feat"
+	TypeFix         = "fix"
+	TypePerformance = "perf"
+
+	footerBreakingChange = "BREAKING CHANGE"
+)
+
+var (
+	// headerRegex matches the header of a commit message: <type>[(<scope>)][!]: <description>
+	headerRegex = regexp.MustCompile(`^([A-Za-z][\w-]*)(?:\(([^()]*)\))?(!)?: +(.+)$`)
+	// footerRegex matches the first line of a footer: <token>: <value> or <token> #<value>
+	footerRegex = regexp.MustCompile(`^(BREAKING[ -]CHANGE|[A-Za-z][\w-]*)(?:: | #)(.*)$`)
+)
+
+// Footer is a trailer of a commit message (e.g. Refs: #123, BREAKING CHANGE: ...)
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Message is a commit message parsed with the Conventional Commits specification (https://www.conventionalcommits.org)
+type Message struct {
+	// Type is empty if the header doesn't follow the specification; the description is then the whole header
+	Type        string
+	Scope       string
+	Description string
+	Body        string
+	Footers     []Footer
+	// Breaking is true if the header contains ! or if there is a BREAKING CHANGE footer
+	Breaking bool
+	// BreakingChange is the text of the BREAKING CHANGE footer(s)
+	BreakingChange string
+}
+
+/*
+Parse a commit message from its subject (the first line) and its body
+  - the footers are read from the last paragraph of the body, if all its lines are footers or continuations of a footer
+  - BREAKING-CHANGE is a synonym of BREAKING CHANGE
+*/
+func Parse(subject, body string) Message {
+	m := Message{Description: strings.TrimSpace(subject)}
+	if match := headerRegex.FindStringSubmatch(m.Description); match != nil {
+		m.Type = strings.ToLower(match[1])
+		m.Scope = strings.TrimSpace(match[2])
+		m.Breaking = match[3] == "!"
+		m.Description = strings.TrimSpace(match[4])
+	}
+
+	m.Body = strings.TrimSpace(body)
+	paragraphs := strings.Split(m.Body, "\n\n")
+	if footers, ok := parseFooters(paragraphs[len(paragraphs)-1]); ok {
+		m.Footers = footers
+		m.Body = strings.TrimSpace(strings.Join(paragraphs[:len(paragraphs)-1], "\n\n"))
+	}
+
+	var breaking []string
+	for _, f := range m.Footers {
+		if f.Token == footerBreakingChange {
+			breaking = append(breaking, f.Value)
+		}
+	}
+	if len(breaking) > 0 {
+		m.Breaking = true
+		m.BreakingChange = strings.Join(breaking, "\n\n")
+	}
+	return m
+}
+
+// parseFooters parses a paragraph made of footers; the lines that don't start a footer are continuations of the previous footer
+func parseFooters(paragraph string) ([]Footer, bool) {
+	var footers []Footer
+	for _, line := range strings.Split(paragraph, "\n") {
+		match := footerRegex.FindStringSubmatch(line)
+		if match == nil {
+			if len(footers) == 0 {
+				return nil, false
+			}
+			footers[len(footers)-1].Value += "\n" + line
+			continue
+		}
+		token := match[1]
+		if token == "BREAKING-CHANGE" {
+			token = footerBreakingChange
+		}
+		footers = append(footers, Footer{Token: token, Value: match[2]})
+	}
+	for i := range footers {
+		footers[i].Value = strings.TrimSpace(footers[i].Value)
+	}
+	return footers, len(footers) > 0
+}
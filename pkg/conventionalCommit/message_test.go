@@ -0,0 +1,45 @@
+package conventionalCommit
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_Parse(t *testing.T) {
+	// arrange
+	tables := []struct {
+		subject string
+		body    string
+
+		want Message
+	}{
+		{"feat: add the export", "", Message{Type: "feat", Description: "add the export"}},
+		{"fix(api): handle errors", "", Message{Type: "fix", Scope: "api", Description: "handle errors"}},
+		{"feat(api)!: drop v1", "", Message{Type: "feat", Scope: "api", Description: "drop v1", Breaking: true}},
+		{"Update README.md", "", Message{Description: "Update README.md"}},
+		{"feat: export", "The export is faster.\n\nBREAKING CHANGE: the format changed\nand the file name too\nRefs: #12",
+			Message{Type: "feat", Description: "export", Body: "The export is faster.", Breaking: true,
+				BreakingChange: "the format changed\nand the file name too",
+				Footers:        []Footer{{"BREAKING CHANGE", "the format changed\nand the file name too"}, {"Refs", "#12"}},
+			}},
+		{"fix: typo", "Closes #42", Message{Type: "fix", Description: "typo", Footers: []Footer{{"Closes", "42"}}}},
+		{"chore: release", "BREAKING-CHANGE: new config", Message{Type: "chore", Description: "release", Breaking: true,
+			BreakingChange: "new config", Footers: []Footer{{"BREAKING CHANGE", "new config"}}}},
+		{"docs: explain", "see: the docs\nfor more", Message{Type: "docs", Description: "explain",
+			Footers: []Footer{{"see", "the docs\nfor more"}}}},
+		{"docs: explain", "This is a sentence: with a colon", Message{Type: "docs", Description: "explain", Body: "This is a sentence: with a colon"}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("subject=%q, body=%q", tb.subject, tb.body), func(t *testing.T) {
+			got := Parse(tb.subject, tb.body)
+
+			// assert
+			if !reflect.DeepEqual(got, tb.want) {
+				t.Errorf("got %#v want %#v", got, tb.want)
+			}
+		})
+	}
+}
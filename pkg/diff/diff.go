@@ -0,0 +1,179 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ContextLines is the number of unchanged lines shown before and after each change
+	ContextLines = 3
+
+	noNewlineMarker = `\ No newline at end of file`
+)
+
+// op is an operation of the edit script that transforms the old lines into the new lines
+type op struct {
+	kind byte // ' ' (equal), '-' (delete) or '+' (insert)
+	line string
+	// oldIndex and newIndex are the positions of the line in the old and the new contents
+	oldIndex int
+	newIndex int
+}
+
+/*
+Unified returns the differences between the old and the new contents of a file in the unified diff format (as git diff does)
+  - the changes are grouped in hunks with ContextLines unchanged lines around them
+  - an empty string is returned if the contents are equal
+*/
+func Unified(path, old, new string) string {
+	if old == new {
+		return ""
+	}
+	oldLines, newLines := splitLines(old), splitLines(new)
+	ops := editScript(oldLines, newLines)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+	for _, h := range hunks(ops) {
+		writeHunk(&b, h)
+	}
+	return b.String()
+}
+
+// splitLines splits the contents in lines; the lines keep their newline character, so that a missing newline at the end of the file is detected
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editScript calculates the shortest edit script with the longest common subsequence; the common prefix and suffix are skipped, so that small changes in large files are cheap
+func editScript(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: ' ', line: a[i], oldIndex: i, newIndex: i})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, op{kind: ' ', line: midA[i], oldIndex: prefix + i, newIndex: prefix + j})
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: '-', line: midA[i], oldIndex: prefix + i, newIndex: prefix + j})
+			i++
+		default:
+			ops = append(ops, op{kind: '+', line: midB[j], oldIndex: prefix + i, newIndex: prefix + j})
+			j++
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		ops = append(ops, op{kind: ' ', line: a[len(a)-suffix+k], oldIndex: len(a) - suffix + k, newIndex: len(b) - suffix + k})
+	}
+	return ops
+}
+
+// hunks groups the operations around the changes; the changes separated by less than 2*ContextLines unchanged lines are in the same hunk
+func hunks(ops []op) [][]op {
+	var result [][]op
+	start, end := -1, -1
+	for k, o := range ops {
+		if o.kind == ' ' {
+			continue
+		}
+		from, to := max(k-ContextLines, 0), min(k+ContextLines+1, len(ops))
+		if start >= 0 && from <= end {
+			end = to
+			continue
+		}
+		if start >= 0 {
+			result = append(result, ops[start:end])
+		}
+		start, end = from, to
+	}
+	if start >= 0 {
+		result = append(result, ops[start:end])
+	}
+	return result
+}
+
+// writeHunk writes the header and the lines of a hunk
+func writeHunk(b *strings.Builder, h []op) {
+	var oldCount, newCount int
+	for _, o := range h {
+		if o.kind != '+' {
+			oldCount++
+		}
+		if o.kind != '-' {
+			newCount++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(h[0].oldIndex, oldCount), hunkRange(h[0].newIndex, newCount))
+	for _, o := range h {
+		b.WriteByte(o.kind)
+		b.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			b.WriteString("\n" + noNewlineMarker + "\n")
+		}
+	}
+}
+
+// hunkRange formats the start line and the number of lines of a hunk; the start line of an empty range is the line before it
+func hunkRange(index, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", index)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", index+1)
+	}
+	return fmt.Sprintf("%d,%d", index+1, count)
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
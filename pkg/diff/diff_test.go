@@ -0,0 +1,40 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_Unified(t *testing.T) {
+	// arrange
+	long := strings.Repeat("line\n", 10)
+	tables := []struct {
+		old string
+		new string
+
+		want string
+	}{
+		{"1.2.3\n", "1.2.3\n", ""},
+		{"1.2.3\n", "1.3.0\n", "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-1.2.3\n+1.3.0\n"},
+		{"1.2.3", "1.3.0", "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-1.2.3\n\\ No newline at end of file\n+1.3.0\n\\ No newline at end of file\n"},
+		{"", "1.0.0\n", "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+1.0.0\n"},
+		{"a\nb\nc\nd\ne\nversion: 1\nf\ng\nh\ni\n", "a\nb\nc\nd\ne\nversion: 2\nf\ng\nh\ni\n",
+			"--- a/f\n+++ b/f\n@@ -3,7 +3,7 @@\n c\n d\n e\n-version: 1\n+version: 2\n f\n g\n h\n"},
+		{"v1\n" + long + "v1\n", "v2\n" + long + "v2\n",
+			"--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-v1\n+v2\n line\n line\n line\n@@ -9,4 +9,4 @@\n line\n line\n line\n-v1\n+v2\n"},
+		{"a\nb\n", "a\nnew\nb\n", "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n a\n+new\n b\n"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("old=%q, new=%q", tb.old, tb.new), func(t *testing.T) {
+			got := Unified("f", tb.old, tb.new)
+
+			// assert
+			if got != tb.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tb.want)
+			}
+		})
+	}
+}
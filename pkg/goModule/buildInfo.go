@@ -0,0 +1,243 @@
+package goModule
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/output"
+	"semtag/pkg/version"
+)
+
+const (
+	NameVersion = "Version"
+	NameCommit  = "Commit"
+	NameDate    = "Date"
+
+	defaultPackageName = "main"
+)
+
+var (
+	ErrVersionFile = errors.New("unable to update the Go version file")
+)
+
+// BuildInfo is the version information of a release that is compiled into a Go binary
+type BuildInfo struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+// values returns the build information by constant name, in the order in which the constants are declared
+func (b BuildInfo) values() []struct{ name, value string } {
+	return []struct{ name, value string }{
+		{NameVersion, b.Version},
+		{NameCommit, b.Commit},
+		{NameDate, b.Date},
+	}
+}
+
+// Ldflags returns the linker flags that set the build information in the string variables Version, Commit and Date of a package (e.g. -X 'example.com/app/build.Version=v1.2.0' -X ...)
+func (b BuildInfo) Ldflags(importPath string) string {
+	var flags []string
+	for _, v := range b.values() {
+		flags = append(flags, fmt.Sprintf("-X '%s.%s=%s'", importPath, v.name, v.value))
+	}
+	return strings.Join(flags, " ")
+}
+
+/*
+VersionFileChange prepares the update of a Go source file that declares the build information as the string constants Version, Commit and Date, without writing the file:
+  - if the file exists, only the values of the constants are replaced and the constants that are missing are added; the rest of the file is preserved
+  - a string variable declared without a value (e.g. var Version string) gets the value set on its declaration; a declaration that can't be updated in place (e.g. a value that isn't a string literal) fails with ErrVersionFile instead of being declared twice
+  - if the file doesn't exist, it is generated; the package name is the one of the other .go files of the directory, or the name of the directory
+  - the output is formatted with gofmt, so updating the file twice with the same build information gives the same result
+*/
+func (b BuildInfo) VersionFileChange(path string) (version.Change, error) {
+	var old, src []byte
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		src = b.generate(packageName(filepath.Dir(path)))
+	} else {
+		f := version.File{Path: path}
+		if old, err = f.Read(); err != nil {
+			return version.Change{}, fmt.Errorf("%v: %v", ErrVersionFile, err)
+		}
+		if src, err = b.replaceValues(path, old); err != nil {
+			return version.Change{}, err
+		}
+	}
+	formatted, err := format.Source(src)
+	if err != nil {
+		return version.Change{}, fmt.Errorf("%v: file=%q: %v", ErrVersionFile, path, err)
+	}
+
+	output.Logger().WithFields(logrus.Fields{
+		"filePath":     path,
+		"buildVersion": b.Version,
+		"buildCommit":  b.Commit,
+		"buildDate":    b.Date,
+	}).Debug("prepared the update of the Go version file")
+	return version.Change{Path: path, Old: string(old), New: string(formatted)}, nil
+}
+
+// generate the source of a new Go version file
+func (b BuildInfo) generate(pkg string) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// Code generated by semtag. DO NOT EDIT.\n\npackage %s\n\n// Build information of the release\nconst (\n", pkg)
+	for _, v := range b.values() {
+		fmt.Fprintf(&sb, "\t%s = %s\n", v.name, strconv.Quote(v.value))
+	}
+	sb.WriteString(")\n")
+	return []byte(sb.String())
+}
+
+// replaceValues replaces the string literals of the build information declared at the top level of the file and sets the value of the string variables declared without one; the declarations that are missing are appended as a new const block
+func (b BuildInfo) replaceValues(path string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("%v: file=%q: %v", ErrVersionFile, path, err)
+	}
+
+	newValues := map[string]string{}
+	for _, v := range b.values() {
+		newValues[v.name] = v.value
+	}
+	unsupported := func(name string, pos token.Pos) error {
+		return fmt.Errorf("%v: file=%q: line=%d: %s must be declared as a string constant or variable with a string literal value", ErrVersionFile, path, fset.Position(pos).Line, name)
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	declared := map[string]bool{}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			if _, ok := newValues[fn.Name.Name]; ok && fn.Recv == nil {
+				return nil, unsupported(fn.Name.Name, fn.Pos())
+			}
+			continue
+		}
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok {
+				if _, ok := newValues[ts.Name.Name]; ok {
+					return nil, unsupported(ts.Name.Name, ts.Pos())
+				}
+				continue
+			}
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			var found bool
+			for _, name := range vs.Names {
+				if _, ok := newValues[name.Name]; ok {
+					found = true
+					declared[name.Name] = true
+				}
+			}
+			if !found {
+				continue
+			}
+
+			// a string variable declared without a value (e.g. var Version string): the values of all its names are set, the other names keep the zero value
+			if len(vs.Values) == 0 {
+				typ, ok := vs.Type.(*ast.Ident)
+				if !ok || typ.Name != "string" || gen.Tok != token.VAR {
+					return nil, unsupported(vs.Names[0].Name, vs.Pos())
+				}
+				var values []string
+				for _, name := range vs.Names {
+					values = append(values, strconv.Quote(newValues[name.Name]))
+				}
+				end := fset.Position(vs.Type.End()).Offset
+				edits = append(edits, edit{end, end, " = " + strings.Join(values, ", ")})
+				continue
+			}
+
+			for i, name := range vs.Names {
+				if _, ok := newValues[name.Name]; !ok {
+					continue
+				}
+				if i >= len(vs.Values) {
+					return nil, unsupported(name.Name, name.Pos())
+				}
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return nil, unsupported(name.Name, name.Pos())
+				}
+				edits = append(edits, edit{fset.Position(lit.Pos()).Offset, fset.Position(lit.End()).Offset, strconv.Quote(newValues[name.Name])})
+			}
+		}
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		out = append(out, src[last:e.start]...)
+		out = append(out, e.text...)
+		last = e.end
+	}
+	out = append(out, src[last:]...)
+
+	var missing []struct{ name, value string }
+	for _, v := range b.values() {
+		if !declared[v.name] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		out = append(out, "\n// Build information of the release\nconst (\n"...)
+		for _, v := range missing {
+			out = append(out, fmt.Sprintf("\t%s = %s\n", v.name, strconv.Quote(v.value))...)
+		}
+		out = append(out, ")\n"...)
+	}
+	return out, nil
+}
+
+// packageName returns the package name of the .go files of a directory (test packages are skipped); if there is none, the directory name is used
+func packageName(dir string) string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, m := range matches {
+		if strings.HasSuffix(m, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), m, nil, parser.PackageClauseOnly)
+		if err == nil {
+			return f.Name.Name
+		}
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return defaultPackageName
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, strings.ToLower(filepath.Base(abs)))
+	if !token.IsIdentifier(name) {
+		return defaultPackageName
+	}
+	return name
+}
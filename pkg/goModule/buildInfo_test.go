@@ -0,0 +1,174 @@
+package goModule
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_VersionFileChange(t *testing.T) {
+	// arrange
+	info := BuildInfo{Version: "v1.3.0", Commit: "0f3a2c1", Date: "2021-06-01T10:00:00Z"}
+	tables := []struct {
+		name     string
+		existing string
+
+		want string
+	}{
+		{"new file", "", `// Code generated by semtag. DO NOT EDIT.
+
+package build
+
+// Build information of the release
+const (
+	Version = "v1.3.0"
+	Commit  = "0f3a2c1"
+	Date    = "2021-06-01T10:00:00Z"
+)
+`},
+		{"existing file", `package build
+
+// Version of the service
+var Version = "dev" // set at release time
+
+const Commit = ""
+
+func String() string { return Version }
+`, `package build
+
+// Version of the service
+var Version = "v1.3.0" // set at release time
+
+const Commit = "0f3a2c1"
+
+func String() string { return Version }
+
+// Build information of the release
+const (
+	Date = "2021-06-01T10:00:00Z"
+)
+`},
+		{"variables without value", `package build
+
+var Version string
+
+var (
+	Commit, Date string // set by -ldflags
+	Other        string
+)
+`, `package build
+
+var Version string = "v1.3.0"
+
+var (
+	Commit, Date string = "0f3a2c1", "2021-06-01T10:00:00Z" // set by -ldflags
+	Other        string
+)
+`},
+		{"variables without value mixed with other names", `package build
+
+var Name, Version string
+`, `package build
+
+var Name, Version string = "", "v1.3.0"
+
+// Build information of the release
+const (
+	Commit = "0f3a2c1"
+	Date   = "2021-06-01T10:00:00Z"
+)
+`},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("name=%q", tb.name), func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "build")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "version.go")
+			if tb.existing != "" {
+				if err := ioutil.WriteFile(path, []byte(tb.existing), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			change, err := info.VersionFileChange(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, []byte(change.New), 0644); err != nil {
+				t.Fatal(err)
+			}
+			again, err := info.VersionFileChange(path)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if change.New != tb.want {
+				t.Errorf("got:\n%s\nwant:\n%s", change.New, tb.want)
+			}
+			if again.New != again.Old {
+				t.Errorf("got:\n%s\nwant no change on the second update", again.New)
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), path, change.New, 0); err != nil {
+				t.Errorf("got %v want a valid Go file", err)
+			}
+		})
+	}
+}
+
+func Test_VersionFileChangeInvalid(t *testing.T) {
+	// arrange
+	info := BuildInfo{Version: "v1.3.0", Commit: "0f3a2c1", Date: "2021-06-01T10:00:00Z"}
+	tables := []struct {
+		existing string
+
+		want string
+	}{
+		{"package build\n\nvar Version = version()\n", "line=3: Version"},
+		{"package build\n\nvar Version int\n", "line=3: Version"},
+		{"package build\n\nvar Commit, Date = \"\", 0\n", "line=3: Date"},
+		{"package build\n\nconst (\n\tVersion = \"dev\"\n\tCommit\n)\n", "line=5: Commit"},
+		{"package build\n\nfunc Date() string { return \"\" }\n", "line=3: Date"},
+		{"package build\n\ntype Version string\n", "line=3: Version"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("existing=%q", tb.existing), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "version.go")
+			if err := ioutil.WriteFile(path, []byte(tb.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := info.VersionFileChange(path)
+
+			// assert
+			if err == nil || !strings.Contains(err.Error(), ErrVersionFile.Error()) || !strings.Contains(err.Error(), tb.want) {
+				t.Errorf("got %v want %v with %q", err, ErrVersionFile, tb.want)
+			}
+		})
+	}
+}
+
+func Test_Ldflags(t *testing.T) {
+	// arrange
+	info := BuildInfo{Version: "v1.3.0", Commit: "0f3a2c1", Date: "2021-06-01T10:00:00Z"}
+	want := "-X 'example.com/app/build.Version=v1.3.0' -X 'example.com/app/build.Commit=0f3a2c1' -X 'example.com/app/build.Date=2021-06-01T10:00:00Z'"
+
+	// act
+	got := info.Ldflags("example.com/app/build")
+
+	// assert
+	if got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,338 @@
+package goModule
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"semtag/pkg/output"
+	"semtag/pkg/version"
+)
+
+var (
+	ErrMigrateMajor = errors.New("unable to migrate the module path to the new major version")
+)
+
+// Migration of a module path to a new major version (e.g. example.com/x -> example.com/x/v2)
+type Migration struct {
+	OldPath string
+	NewPath string
+	// Changes contains the files rewritten by the migration: the go.mod files and the .go files that import packages of the module
+	Changes version.Changes
+}
+
+// Migrations of several modules, in the order in which they were prepared
+type Migrations []Migration
+
+// MajorPath returns the module path for a major version: the major version suffix is added, replaced or removed as required by Go (e.g. example.com/x -> example.com/x/v2, gopkg.in/yaml.v2 -> gopkg.in/yaml.v3)
+func (m Module) MajorPath(major int) (string, error) {
+	prefix, pathMajor, ok := module.SplitPathVersion(m.Path)
+	if !ok {
+		return "", fmt.Errorf("%v: invalid module path %q", ErrMigrateMajor, m.Path)
+	}
+
+	if strings.HasPrefix(pathMajor, ".") || strings.HasPrefix(m.Path, "gopkg.in/") {
+		return fmt.Sprintf("%s.v%d", prefix, major), nil
+	}
+	if major <= 1 {
+		return prefix, nil
+	}
+	return fmt.Sprintf("%s/v%d", prefix, major), nil
+}
+
+/*
+MigrateMajor prepares the migration of the module to a new major version, without writing any file:
+  - the module directive of go.mod is rewritten to the new module path
+  - the import paths of the module's packages are rewritten in all the .go files of the module and of the other modules of the repository; the files are parsed with the Go parser and only the import paths are replaced, so that the formatting and comments are preserved
+  - the requirements (and the replacements) of the module in the go.mod files of the other modules are rewritten to the new module path and to the new version (e.g. v2.0.0), so that the repository still builds
+  - the files of the nested modules (e.g. lib inside the module directory) are not changed, unless they depend on the module
+  - the files already changed by the previous migrations are read from their changes, so that a file can be changed by several migrations
+*/
+func (m Module) MigrateMajor(major int, newVersion string, modules []Module, previous Migrations) (Migration, error) {
+	newPath, err := m.MajorPath(major)
+	if err != nil {
+		return Migration{}, err
+	}
+	migration := Migration{
+		OldPath: m.Path,
+		NewPath: newPath,
+	}
+	if newPath == m.Path {
+		return migration, nil
+	}
+	pending := previous.Changes()
+
+	modFilePath := filepath.Join(filepath.FromSlash(m.Dir), ModFileName)
+	dat, err := pending.Read(modFilePath)
+	if err != nil {
+		return Migration{}, fmt.Errorf("%v: %v", ErrMigrateMajor, err)
+	}
+	newModFile, err := rewriteModuleDirective(modFilePath, dat, newPath)
+	if err != nil {
+		return Migration{}, err
+	}
+	migration.add(modFilePath, dat, newModFile)
+
+	var nestedPaths []string
+	for _, other := range modules {
+		if other.Path != m.Path && strings.HasPrefix(other.Path, m.Path+"/") {
+			nestedPaths = append(nestedPaths, other.Path)
+		}
+	}
+
+	for _, other := range modules {
+		if other.Path != m.Path && !other.requires(m.Path) {
+			continue
+		}
+		if other.Path != m.Path {
+			otherModFile := filepath.Join(filepath.FromSlash(other.Dir), ModFileName)
+			dat, err := pending.Read(otherModFile)
+			if err != nil {
+				return Migration{}, fmt.Errorf("%v: %v", ErrMigrateMajor, err)
+			}
+			newDat, err := rewriteRequirements(otherModFile, dat, m.Path, newPath, newVersion)
+			if err != nil {
+				return Migration{}, err
+			}
+			migration.add(otherModFile, dat, newDat)
+		}
+
+		err := other.walkGoFiles(modules, func(p string) error {
+			src, err := pending.Read(p)
+			if err != nil {
+				return err
+			}
+			newSrc, changed, err := rewriteImports(p, src, m.Path, newPath, nestedPaths)
+			if err != nil {
+				return err
+			}
+			if changed {
+				migration.add(p, src, newSrc)
+			}
+			return nil
+		})
+		if err != nil {
+			return Migration{}, fmt.Errorf("%v: %v", ErrMigrateMajor, err)
+		}
+	}
+
+	output.Logger().WithFields(logrus.Fields{
+		"moduleOldPath":  migration.OldPath,
+		"moduleNewPath":  migration.NewPath,
+		"migrationFiles": migration.Changes.Paths(),
+	}).Debug("prepared the major version migration of the module")
+	return migration, nil
+}
+
+// requires checks if the module requires the module path
+func (m Module) requires(modulePath string) bool {
+	return isOneOf(modulePath, m.Requires)
+}
+
+// walkGoFiles calls fn for every .go file of the module; the vendor and testdata directories, the hidden directories and the directories of the nested modules are skipped
+func (m Module) walkGoFiles(modules []Module, fn func(p string) error) error {
+	var nestedDirs []string
+	for _, other := range modules {
+		if other.Dir != m.Dir && isSubDir(m.Dir, other.Dir) {
+			nestedDirs = append(nestedDirs, filepath.FromSlash(other.Dir))
+		}
+	}
+
+	root := filepath.FromSlash(m.Dir)
+	return filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if p != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || isOneOf(p, nestedDirs)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".go" {
+			return nil
+		}
+		return fn(p)
+	})
+}
+
+func (mig *Migration) add(p string, old, new []byte) {
+	if string(old) == string(new) {
+		return
+	}
+	mig.Changes = append(mig.Changes, version.Change{Path: p, Old: string(old), New: string(new)})
+}
+
+// NewPaths returns the new module paths of the migrations
+func (migs Migrations) NewPaths() []string {
+	var paths []string
+	for _, mig := range migs {
+		paths = append(paths, mig.NewPath)
+	}
+	return paths
+}
+
+// Changes returns the changes of all the migrations, with a single change per file: the original contents of the file and its contents after the last migration
+func (migs Migrations) Changes() version.Changes {
+	var changes version.Changes
+	index := map[string]int{}
+	for _, mig := range migs {
+		for _, c := range mig.Changes {
+			if i, ok := index[c.Path]; ok {
+				changes[i].New = c.New
+				continue
+			}
+			index[c.Path] = len(changes)
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// rewriteModuleDirective replaces the path of the module directive and leaves the rest of the go.mod file untouched
+func rewriteModuleDirective(modFilePath string, dat []byte, newPath string) ([]byte, error) {
+	f, err := modfile.ParseLax(modFilePath, dat, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%v: file=%q: %v", ErrParseModFile, modFilePath, err)
+	}
+	if f.Module == nil || f.Module.Syntax == nil {
+		return nil, fmt.Errorf("%v: file=%q: no module directive found", ErrParseModFile, modFilePath)
+	}
+
+	start, end := f.Module.Syntax.Start.Byte, f.Module.Syntax.End.Byte
+	line := string(dat[start:end])
+	oldToken := f.Module.Syntax.Token[len(f.Module.Syntax.Token)-1]
+	i := strings.LastIndex(line, oldToken)
+	if i < 0 {
+		return nil, fmt.Errorf("%v: file=%q: module path %q not found", ErrParseModFile, modFilePath, oldToken)
+	}
+	newLine := line[:i] + modfile.AutoQuote(newPath) + line[i+len(oldToken):]
+
+	var out []byte
+	out = append(out, dat[:start]...)
+	out = append(out, newLine...)
+	out = append(out, dat[end:]...)
+	return out, nil
+}
+
+// rewriteRequirements replaces the module path and the version of the requirements (and of the replacements) of a module and leaves the rest of the go.mod file untouched
+func rewriteRequirements(modFilePath string, dat []byte, oldPath, newPath, newVersion string) ([]byte, error) {
+	f, err := modfile.Parse(modFilePath, dat, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%v: file=%q: %v", ErrParseModFile, modFilePath, err)
+	}
+
+	type edit struct {
+		start, end int
+		line       string
+	}
+	var edits []edit
+	replace := func(line *modfile.Line, oldTokens, newTokens []string) error {
+		start, end := line.Start.Byte, line.End.Byte
+		text := string(dat[start:end])
+		var out string
+		for i, tok := range oldTokens {
+			j := strings.Index(text, tok)
+			if j < 0 {
+				return fmt.Errorf("%v: file=%q: %q not found", ErrParseModFile, modFilePath, tok)
+			}
+			out += text[:j] + newTokens[i]
+			text = text[j+len(tok):]
+		}
+		edits = append(edits, edit{start: start, end: end, line: out + text})
+		return nil
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path != oldPath || r.Syntax == nil {
+			continue
+		}
+		if err := replace(r.Syntax, []string{modfile.AutoQuote(oldPath), r.Mod.Version}, []string{modfile.AutoQuote(newPath), newVersion}); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range f.Replace {
+		if r.Old.Path != oldPath || r.Syntax == nil {
+			continue
+		}
+		oldTokens, newTokens := []string{modfile.AutoQuote(oldPath)}, []string{modfile.AutoQuote(newPath)}
+		if r.Old.Version != "" {
+			oldTokens, newTokens = append(oldTokens, r.Old.Version), append(newTokens, newVersion)
+		}
+		if err := replace(r.Syntax, oldTokens, newTokens); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	out := append([]byte{}, dat...)
+	for _, e := range edits {
+		out = append(out[:e.start:e.start], append([]byte(e.line), out[e.end:]...)...)
+	}
+	return out, nil
+}
+
+// rewriteImports replaces the import paths that point to packages of the old module path; the imports of the nested modules are skipped
+func rewriteImports(fileName string, src []byte, oldPath, newPath string, nestedPaths []string) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fileName, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var out []byte
+	last := 0
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, false, err
+		}
+		if importPath != oldPath && !strings.HasPrefix(importPath, oldPath+"/") {
+			continue
+		}
+		if isOneOf(importPath, nestedPaths) || hasAnyPrefix(importPath, nestedPaths) {
+			continue
+		}
+
+		start := fset.Position(imp.Path.Pos()).Offset
+		end := fset.Position(imp.Path.End()).Offset
+		out = append(out, src[last:start]...)
+		out = append(out, strconv.Quote(newPath+strings.TrimPrefix(importPath, oldPath))...)
+		last = end
+	}
+	if last == 0 {
+		return src, false, nil
+	}
+	out = append(out, src[last:]...)
+	return out, true, nil
+}
+
+func isOneOf(s string, list []string) bool {
+	for _, l := range list {
+		if s == l {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package goModule
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_MajorPath(t *testing.T) {
+	// arrange
+	tables := []struct {
+		path  string
+		major int
+
+		want string
+	}{
+		{"example.com/x", 2, "example.com/x/v2"},
+		{"example.com/x/v2", 3, "example.com/x/v3"},
+		{"example.com/x/v2", 1, "example.com/x"},
+		{"gopkg.in/yaml.v2", 3, "gopkg.in/yaml.v3"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("path=%q, major=%d, want=%q", tb.path, tb.major, tb.want), func(t *testing.T) {
+			m := Module{Dir: ".", Path: tb.path}
+
+			got, err := m.MajorPath(tb.major)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tb.want {
+				t.Errorf("got %q want %q", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_RewriteModuleDirective(t *testing.T) {
+	// arrange
+	modFile := `// the main module
+module example.com/x // keep this comment
+
+go 1.16
+
+require example.com/x/lib v1.0.0
+`
+	want := `// the main module
+module example.com/x/v2 // keep this comment
+
+go 1.16
+
+require example.com/x/lib v1.0.0
+`
+
+	// act
+	got, err := rewriteModuleDirective("go.mod", []byte(modFile), "example.com/x/v2")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func Test_RewriteImports(t *testing.T) {
+	// arrange
+	src := `package main
+
+import (
+	"fmt"
+
+	// the root package
+	x "example.com/x"
+	"example.com/x/internal/thing"
+	"example.com/x/lib/client"
+	"example.com/xyz"
+)
+
+// main keeps "example.com/x/internal/thing" in a comment
+func main() {
+	fmt.Println(x.Name, thing.Name, client.Name, "example.com/x")
+}
+`
+	want := `package main
+
+import (
+	"fmt"
+
+	// the root package
+	x "example.com/x/v2"
+	"example.com/x/v2/internal/thing"
+	"example.com/x/lib/client"
+	"example.com/xyz"
+)
+
+// main keeps "example.com/x/internal/thing" in a comment
+func main() {
+	fmt.Println(x.Name, thing.Name, client.Name, "example.com/x")
+}
+`
+
+	// act
+	got, changed, err := rewriteImports("main.go", []byte(src), "example.com/x", "example.com/x/v2", []string{"example.com/x/lib"})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("expected the imports to be changed")
+	}
+	if string(got) != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
+
+func Test_RewriteRequirements(t *testing.T) {
+	// arrange
+	modFile := `module example.com/app
+
+go 1.16
+
+require (
+	example.com/x v1.4.2 // the library
+	example.com/xyz v1.0.0
+)
+
+replace example.com/x => ../x
+`
+	want := `module example.com/app
+
+go 1.16
+
+require (
+	example.com/x/v2 v2.0.0 // the library
+	example.com/xyz v1.0.0
+)
+
+replace example.com/x/v2 => ../x
+`
+
+	// act
+	got, err := rewriteRequirements("go.mod", []byte(modFile), "example.com/x", "example.com/x/v2", "v2.0.0")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
+
+func Test_MigrateMajorDependents(t *testing.T) {
+	// arrange
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/x\n\ngo 1.16\n",
+		"x.go":           "package x\n\nimport _ \"example.com/x/internal\"\n",
+		"app/go.mod":     "module example.com/x/app\n\ngo 1.16\n\nrequire example.com/x v1.4.2\n",
+		"app/main.go":    "package main\n\nimport \"example.com/x\"\n\nvar _ = x.Name\n",
+		"other/go.mod":   "module example.com/other\n\ngo 1.16\n",
+		"other/other.go": "package other\n\nimport \"example.com/x\"\n",
+	}
+	for name, contents := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	modules := []Module{
+		{Dir: root, Path: "example.com/x"},
+		{Dir: root + "/app", Path: "example.com/x/app", Requires: []string{"example.com/x"}},
+		{Dir: root + "/other", Path: "example.com/other"},
+	}
+	// the app module is also migrated to v3
+	app, err := modules[1].MigrateMajor(3, "v3.0.0", modules, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	mig, err := modules[0].MigrateMajor(2, "v2.0.0", modules, Migrations{app})
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, c := range (Migrations{app, mig}).Changes() {
+		rel, _ := filepath.Rel(root, c.Path)
+		got[filepath.ToSlash(rel)] = c.New
+	}
+	want := map[string]string{
+		"go.mod":      "module example.com/x/v2\n\ngo 1.16\n",
+		"x.go":        "package x\n\nimport _ \"example.com/x/v2/internal\"\n",
+		"app/go.mod":  "module example.com/x/app/v3\n\ngo 1.16\n\nrequire example.com/x/v2 v2.0.0\n",
+		"app/main.go": "package main\n\nimport \"example.com/x/v2\"\n\nvar _ = x.Name\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package goModule
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"semtag/pkg/component"
+	"semtag/pkg/output"
+)
+
+const (
+	ModFileName = "go.mod"
+
+	// RootDir is the directory of the module found at the root of the repository
+	RootDir = "."
+	// TagPrefix is the prefix required by Go for the version part of a module tag (e.g. v1.2.3, sub/dir/v1.2.3)
+	TagPrefix = "v"
+)
+
+var (
+	ErrParseModFile         = errors.New("unable to parse the go.mod file")
+	ErrMajorVersionMismatch = errors.New("the major version doesn't match the major version suffix of the module path")
+)
+
+// Module is a Go module found in the repository
+type Module struct {
+	// Dir of the module relative to the repository root, using forward slashes (e.g. sub/dir or . for the root module)
+	Dir string
+	// Path of the module as declared in go.mod (e.g. example.com/repo/sub/dir/v2)
+	Path string
+	// Requires contains the paths of the modules required by this module
+	Requires []string
+}
+
+// Discover walks the repository and returns all the Go modules, sorted by their directory. The vendor and testdata directories and the directories ignored by the Go tool (starting with . or _) are skipped
+func Discover(root string) ([]Module, error) {
+	var modules []Module
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if p != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() != ModFileName {
+			return nil
+		}
+
+		dir, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		m, err := Load(filepath.ToSlash(dir), p)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Dir < modules[j].Dir
+	})
+	output.Logger().WithFields(logrus.Fields{
+		"root":    root,
+		"modules": fmt.Sprintf("%v", modules),
+	}).Debug("discovered the Go modules")
+	return modules, nil
+}
+
+// Load the module from a go.mod file
+func Load(dir string, modFilePath string) (Module, error) {
+	dat, err := ioutil.ReadFile(modFilePath)
+	if err != nil {
+		return Module{}, fmt.Errorf("%v: file=%q: %v", ErrParseModFile, modFilePath, err)
+	}
+	f, err := modfile.ParseLax(modFilePath, dat, nil)
+	if err != nil {
+		return Module{}, fmt.Errorf("%v: file=%q: %v", ErrParseModFile, modFilePath, err)
+	}
+	if f.Module == nil {
+		return Module{}, fmt.Errorf("%v: file=%q: no module directive found", ErrParseModFile, modFilePath)
+	}
+
+	m := Module{
+		Dir:  dir,
+		Path: f.Module.Mod.Path,
+	}
+	for _, r := range f.Require {
+		m.Requires = append(m.Requires, r.Mod.Path)
+	}
+	return m, nil
+}
+
+func (m Module) String() string {
+	return m.Path + " (" + m.Dir + ")"
+}
+
+// IsRoot checks if the module is found at the root of the repository
+func (m Module) IsRoot() bool {
+	return m.Dir == RootDir || m.Dir == ""
+}
+
+// TagPrefix returns the prefix of the git tags of the module: v for the root module and <dir>/v for nested modules (e.g. sub/dir/v)
+func (m Module) TagPrefix() string {
+	if m.IsRoot() {
+		return TagPrefix
+	}
+	return m.Dir + "/" + TagPrefix
+}
+
+// PathMajor returns the major version declared by the /vN suffix of the module path; paths without a suffix are at major version 0 or 1
+func (m Module) PathMajor() int {
+	_, pathMajor, ok := module.SplitPathVersion(m.Path)
+	if !ok || pathMajor == "" {
+		return 1
+	}
+	var major int
+	if _, err := fmt.Sscanf(strings.TrimLeft(pathMajor, "./"), "v%d", &major); err != nil {
+		return 1
+	}
+	return major
+}
+
+/*
+ValidateMajor checks if a major version can be tagged for the module:
+  - major versions 0 and 1 require a module path without a major version suffix
+  - major versions 2 and above require the module path to end with the matching /vN suffix (e.g. example.com/x/v2)
+*/
+func (m Module) ValidateMajor(major int) error {
+	pathMajor := m.PathMajor()
+	if major <= 1 && pathMajor <= 1 {
+		return nil
+	}
+	if major == pathMajor {
+		return nil
+	}
+	return fmt.Errorf("%v: module=%q, major=%d, expected module path suffix=\"/v%d\"", ErrMajorVersionMismatch, m.Path, major, major)
+}
+
+/*
+Components converts the Go modules to monorepo components:
+  - each module is named after its module path and is tagged with its Go tag prefix
+  - a module is relevant for the changes in its own directory, excluding the directories of the nested modules
+  - a module depends on the other modules of the repository that it requires
+  - a module with a major version suffix (e.g. example.com/x/v2) that has never been released starts at its major version (e.g. 2.0.0)
+*/
+func Components(modules []Module) component.List {
+	paths := map[string]bool{}
+	for _, m := range modules {
+		paths[m.Path] = true
+	}
+
+	var list component.List
+	for _, m := range modules {
+		c := component.Component{
+			Name:   m.Path,
+			Prefix: m.TagPrefix(),
+			Paths:  []string{m.Dir},
+		}
+		if major := m.PathMajor(); major > 1 {
+			c.InitialVersion = fmt.Sprintf("%d.0.0", major)
+		}
+		for _, nested := range modules {
+			if nested.Dir != m.Dir && isSubDir(m.Dir, nested.Dir) {
+				c.Paths = append(c.Paths, ":(exclude)"+nested.Dir)
+			}
+		}
+		for _, r := range m.Requires {
+			if paths[r] && r != m.Path {
+				c.DependsOn = append(c.DependsOn, r)
+			}
+		}
+		list = append(list, c)
+	}
+	return list
+}
+
+// isSubDir checks if a directory is found inside the parent directory (both relative to the repository root)
+func isSubDir(parent, dir string) bool {
+	if parent == RootDir || parent == "" {
+		return true
+	}
+	return strings.HasPrefix(path.Clean(dir)+"/", path.Clean(parent)+"/")
+}
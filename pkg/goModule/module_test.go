@@ -0,0 +1,118 @@
+package goModule
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_Discover(t *testing.T) {
+	// arrange
+	root := t.TempDir()
+	modFiles := map[string]string{
+		"go.mod":                 "module example.com/repo\n\ngo 1.16\n\nrequire example.com/repo/lib v1.0.0\n",
+		"lib/go.mod":             "module example.com/repo/lib\n\ngo 1.16\n",
+		"tools/cli/go.mod":       "module example.com/repo/tools/cli/v2\n\ngo 1.16\n",
+		"vendor/x/go.mod":        "module example.com/x\n",
+		"lib/testdata/go.mod":    "module example.com/testdata\n",
+		".hidden/go.mod":         "module example.com/hidden\n",
+		"lib/internal/readme.md": "not a module",
+	}
+	for name, contents := range modFiles {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// act
+	modules, err := Discover(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	want := []Module{
+		{Dir: ".", Path: "example.com/repo", Requires: []string{"example.com/repo/lib"}},
+		{Dir: "lib", Path: "example.com/repo/lib"},
+		{Dir: "tools/cli", Path: "example.com/repo/tools/cli/v2"},
+	}
+	if !reflect.DeepEqual(modules, want) {
+		t.Errorf("got %#v want %#v", modules, want)
+	}
+
+	components := Components(modules)
+	wantPaths := []string{".", ":(exclude)lib", ":(exclude)tools/cli"}
+	if !reflect.DeepEqual(components[0].Paths, wantPaths) {
+		t.Errorf("got %v want %v", components[0].Paths, wantPaths)
+	}
+	if !reflect.DeepEqual(components[0].DependsOn, []string{"example.com/repo/lib"}) {
+		t.Errorf("got %v want the root module to depend on the lib module", components[0].DependsOn)
+	}
+	if components[0].InitialVersion != "" || components[2].InitialVersion != "2.0.0" {
+		t.Errorf("got initial versions %q and %q want the default version and 2.0.0", components[0].InitialVersion, components[2].InitialVersion)
+	}
+}
+
+func Test_TagPrefix(t *testing.T) {
+	// arrange
+	tables := []struct {
+		module Module
+
+		want string
+	}{
+		{Module{Dir: ".", Path: "example.com/repo"}, "v"},
+		{Module{Dir: "lib", Path: "example.com/repo/lib"}, "lib/v"},
+		{Module{Dir: "tools/cli", Path: "example.com/repo/tools/cli/v2"}, "tools/cli/v"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("module=%s, want=%q", tb.module, tb.want), func(t *testing.T) {
+			got := tb.module.TagPrefix()
+
+			// assert
+			if got != tb.want {
+				t.Errorf("got %q want %q", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_ValidateMajor(t *testing.T) {
+	// arrange
+	tables := []struct {
+		path  string
+		major int
+
+		wantError bool
+	}{
+		{"example.com/x", 0, false},
+		{"example.com/x", 1, false},
+		{"example.com/x", 2, true},
+		{"example.com/x/v2", 2, false},
+		{"example.com/x/v2", 3, true},
+		{"example.com/x/v3", 1, true},
+		{"gopkg.in/yaml.v3", 3, false},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("path=%q, major=%d, wantError=%t", tb.path, tb.major, tb.wantError), func(t *testing.T) {
+			m := Module{Dir: ".", Path: tb.path}
+
+			err := m.ValidateMajor(tb.major)
+
+			// assert
+			if (err != nil) != tb.wantError {
+				t.Errorf("got error %v want error %t", err, tb.wantError)
+			}
+		})
+	}
+}
@@ -38,7 +38,8 @@ func initLogger() {
 		log.SetReportCaller(true)
 	}
 
-	log.Out = os.Stdout
+	// the logs are written to stderr, so that stdout contains only the result (e.g. the version number or the release notes)
+	log.Out = os.Stderr
 
 	Logger().WithFields(logrus.Fields{
 		"logLevel":        log.Level.String(),
@@ -0,0 +1,118 @@
+package release
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	// PlanSchemaVersion is the version of the schema of the JSON release plan; it is increased if a field is removed or changes its meaning
+	PlanSchemaVersion = 1
+
+	ActionTag             = "tag"
+	ActionFile            = "file"
+	ActionGoModuleMigrate = "goModuleMigration"
+	ActionChangelog       = "changelog"
+	ActionReleaseNotes    = "releaseNotes"
+	ActionCommand         = "command"
+)
+
+/*
+Plan is the machine-readable result of a run, printed as a single JSON document
+  - the release of the repository is described by the fields of Release; they are empty if the components of a monorepo (or the Go modules) are released
+  - the released components are listed in Components, in the order in which they are released
+  - the lists are never null, so that the schema is stable
+*/
+type Plan struct {
+	SchemaVersion int `json:"schemaVersion"`
+	Release
+	Components []Release `json:"components"`
+	// DryRun is true if the actions were only printed
+	DryRun bool `json:"dryRun"`
+	// FilesChanged are the files updated (or that would be updated in dry-run mode) with the version
+	FilesChanged []string `json:"filesChanged"`
+	Actions      []Action `json:"actions"`
+	// ReleaseNotes of the new version, if they are requested
+	ReleaseNotes string `json:"releaseNotes"`
+}
+
+// Release is the release of the repository or of a component
+type Release struct {
+	// Component is the name of the component; it is empty for the repository
+	Component string `json:"component"`
+	// PreviousVersion is the version number before the increment, without the prefix and the suffix
+	PreviousVersion string `json:"previousVersion"`
+	// PreviousTag is the latest version tag; it is empty if the repository (or the component) has never been released
+	PreviousTag string `json:"previousTag"`
+	// NextVersion is the version number after the increment, without the prefix and the suffix (the version printed by the text output)
+	NextVersion string `json:"nextVersion"`
+	// Scope of the increment: none, major, minor or patch
+	Scope string `json:"scope"`
+	// Versions are all the variants of the next version (see the -command flag)
+	Versions []string `json:"versions"`
+	// Tag is the git tag of the next version
+	Tag string `json:"tag"`
+	// ReleaseNeeded is true if the next version differs from the previous version and relevant changes are found
+	ReleaseNeeded bool `json:"releaseNeeded"`
+	// TriggeredBy contains all the dependencies of a component released in the same plan; the component is released because of them, even if it also has its own changes
+	TriggeredBy []string `json:"triggeredBy"`
+	// CommitsAnalyzed are the commits since the previous tag
+	CommitsAnalyzed []Commit `json:"commitsAnalyzed"`
+}
+
+type Commit struct {
+	Hash      string `json:"hash"`
+	ShortHash string `json:"shortHash"`
+	Subject   string `json:"subject"`
+}
+
+// Action performed during the run (or printed in dry-run mode)
+type Action struct {
+	// Type of the action: tag, file, goModuleMigration, changelog, releaseNotes or command
+	Type string `json:"type"`
+	// Target of the action: the tag, the file, the module path or the command
+	Target string `json:"target"`
+	// Pushed is true if the result of the action is pushed to the remote
+	Pushed bool `json:"pushed"`
+}
+
+// NewPlan returns an empty plan with the current schema version
+func NewPlan() Plan {
+	return Plan{SchemaVersion: PlanSchemaVersion}
+}
+
+// Write writes the plan as an indented JSON document
+func (p Plan) Write(w io.Writer) error {
+	p.Release = p.Release.withLists()
+	for i, r := range p.Components {
+		p.Components[i] = r.withLists()
+	}
+	if p.Components == nil {
+		p.Components = []Release{}
+	}
+	if p.FilesChanged == nil {
+		p.FilesChanged = []string{}
+	}
+	if p.Actions == nil {
+		p.Actions = []Action{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(p)
+}
+
+// withLists returns the release with empty lists instead of null lists
+func (r Release) withLists() Release {
+	if r.Versions == nil {
+		r.Versions = []string{}
+	}
+	if r.TriggeredBy == nil {
+		r.TriggeredBy = []string{}
+	}
+	if r.CommitsAnalyzed == nil {
+		r.CommitsAnalyzed = []Commit{}
+	}
+	return r
+}
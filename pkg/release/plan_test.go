@@ -0,0 +1,88 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_PlanWrite(t *testing.T) {
+	// arrange
+	tables := []struct {
+		name string
+		plan Plan
+
+		wantLists []string
+	}{
+		{"empty plan", NewPlan(), []string{"versions", "triggeredBy", "commitsAnalyzed", "components", "filesChanged", "actions"}},
+		{"component plan", Plan{SchemaVersion: PlanSchemaVersion, Components: []Release{{Component: "api"}}}, []string{"components", "filesChanged", "actions"}},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tb.plan.Write(&buf)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(buf.String(), "null") {
+				t.Errorf("got a null value in %s", buf.String())
+			}
+			got := map[string]interface{}{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got["schemaVersion"] != float64(PlanSchemaVersion) {
+				t.Errorf("got schemaVersion %v want %d", got["schemaVersion"], PlanSchemaVersion)
+			}
+			for _, key := range tb.wantLists {
+				if _, ok := got[key].([]interface{}); !ok {
+					t.Errorf("got %s=%v want a list", key, got[key])
+				}
+			}
+		})
+	}
+}
+
+func Test_PlanWriteRelease(t *testing.T) {
+	// arrange
+	plan := NewPlan()
+	plan.Release = Release{
+		PreviousVersion: "1.2.3",
+		PreviousTag:     "v1.2.3",
+		NextVersion:     "1.3.0",
+		Scope:           "minor",
+		Versions:        []string{"v1.3.0", "v1.3", "v1"},
+		Tag:             "v1.3.0",
+		ReleaseNeeded:   true,
+		CommitsAnalyzed: []Commit{{Hash: "0123456789abcdef", ShortHash: "0123456", Subject: "feat: <html> & more"}},
+	}
+	plan.Actions = []Action{{Type: ActionTag, Target: "v1.3.0", Pushed: true}}
+
+	// act
+	var buf bytes.Buffer
+	err := plan.Write(&buf)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "feat: <html> & more") {
+		t.Errorf("got %s want the subject without HTML escaping", buf.String())
+	}
+	got := Plan{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Release, plan.Release.withLists()) {
+		t.Errorf("got %+v want %+v", got.Release, plan.Release)
+	}
+	if !reflect.DeepEqual(got.Actions, plan.Actions) {
+		t.Errorf("got %+v want %+v", got.Actions, plan.Actions)
+	}
+}
@@ -0,0 +1,171 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	HostGitHub    = "github"
+	HostGitLab    = "gitlab"
+	HostBitbucket = "bitbucket"
+	HostGitea     = "gitea"
+
+	// Placeholders of the URL patterns
+	PlaceholderHash     = "{hash}"
+	PlaceholderTag      = "{tag}"
+	PlaceholderPrevious = "{previous}"
+	PlaceholderId       = "{id}"
+)
+
+var (
+	ErrParseRemoteUrl  = errors.New("remote URL can't be parsed")
+	ErrUnknownHostType = errors.New("unknown git host type")
+
+	// scpLikeUrl is the short SSH syntax of git (e.g. git@github.com:org/repo.git)
+	scpLikeUrl = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+	// Presets contains the URL patterns of the supported git hosts by host type; {base} is replaced by the web URL of the repository
+	Presets = map[string]Links{
+		HostGitHub: {
+			Commit:  "{base}/commit/" + PlaceholderHash,
+			Tag:     "{base}/releases/tag/" + PlaceholderTag,
+			Compare: "{base}/compare/" + PlaceholderPrevious + "..." + PlaceholderTag,
+			Issue:   "{base}/issues/" + PlaceholderId,
+		},
+		HostGitLab: {
+			Commit:       "{base}/-/commit/" + PlaceholderHash,
+			Tag:          "{base}/-/tags/" + PlaceholderTag,
+			Compare:      "{base}/-/compare/" + PlaceholderPrevious + "..." + PlaceholderTag,
+			Issue:        "{base}/-/issues/" + PlaceholderId,
+			MergeRequest: "{base}/-/merge_requests/" + PlaceholderId,
+		},
+		HostBitbucket: {
+			Commit:  "{base}/commits/" + PlaceholderHash,
+			Tag:     "{base}/src/" + PlaceholderTag,
+			Compare: "{base}/branches/compare/" + PlaceholderTag + "%0D" + PlaceholderPrevious,
+			Issue:   "{base}/issues/" + PlaceholderId,
+		},
+		HostGitea: {
+			Commit:  "{base}/commit/" + PlaceholderHash,
+			Tag:     "{base}/src/tag/" + PlaceholderTag,
+			Compare: "{base}/compare/" + PlaceholderPrevious + "..." + PlaceholderTag,
+			Issue:   "{base}/issues/" + PlaceholderId,
+		},
+	}
+
+	// knownHosts maps the public instances and the usual host names of the self-hosted instances to the host type
+	knownHosts = []struct{ pattern, hostType string }{
+		{"github", HostGitHub},
+		{"gitlab", HostGitLab},
+		{"bitbucket", HostBitbucket},
+		{"gitea", HostGitea},
+		{"codeberg.org", HostGitea},
+	}
+)
+
+// Links contains the URL patterns of the web pages of a repository; a pattern is empty if the page can't be linked
+type Links struct {
+	// Commit is the URL of a commit, with the placeholder {hash}
+	Commit string
+	// Tag is the URL of a tag, with the placeholder {tag}
+	Tag string
+	// Compare is the URL of the comparison of two tags, with the placeholders {previous} and {tag}
+	Compare string
+	// Issue is the URL of an issue, with the placeholder {id}
+	Issue string
+	// MergeRequest is the URL of a merge request referenced with the GitLab syntax (e.g. !45), with the placeholder {id}
+	MergeRequest string
+}
+
+// Repository is a git repository hosted on a web-based git host
+type Repository struct {
+	// Host name without the port of the SSH URLs (e.g. github.com)
+	Host string
+	// Path of the repository without the .git extension (e.g. org/repo)
+	Path string
+	// HostType is one of the supported host types (e.g. github); it is empty if the host can't be detected
+	HostType string
+	// WebUrl is the URL of the web page of the repository (e.g. https://github.com/org/repo)
+	WebUrl string
+}
+
+/*
+ParseUrl parses the URL of a git remote; the supported formats are:
+  - HTTP(S): https://github.com/org/repo.git (credentials are removed)
+  - SSH: ssh://git@github.com:22/org/repo.git
+  - short SSH syntax: git@github.com:org/repo.git
+
+The host type is detected from the host name (e.g. gitlab.example.com is a GitLab instance)
+*/
+func ParseUrl(remoteUrl string) (Repository, error) {
+	remoteUrl = strings.TrimSpace(remoteUrl)
+	var r Repository
+	scheme := "https"
+	if strings.Contains(remoteUrl, "://") {
+		u, err := url.Parse(remoteUrl)
+		if err != nil {
+			return Repository{}, fmt.Errorf("%v: url=%q: %v", ErrParseRemoteUrl, remoteUrl, err)
+		}
+		r.Host = u.Hostname()
+		if u.Scheme == "http" || u.Scheme == "https" {
+			// the web server of an HTTP(S) remote is the same as the git server, including its port
+			r.Host = u.Host
+			scheme = u.Scheme
+		}
+		r.Path = u.Path
+	} else if match := scpLikeUrl.FindStringSubmatch(remoteUrl); match != nil {
+		r.Host, r.Path = match[1], match[2]
+	}
+
+	r.Path = strings.TrimSuffix(strings.Trim(r.Path, "/"), ".git")
+	if r.Host == "" || r.Path == "" {
+		return Repository{}, fmt.Errorf("%v: url=%q", ErrParseRemoteUrl, remoteUrl)
+	}
+	r.WebUrl = fmt.Sprintf("%s://%s/%s", scheme, r.Host, r.Path)
+	r.HostType = DetectHostType(r.Host)
+	return r, nil
+}
+
+// DetectHostType returns the host type of a host name, or an empty string if the host is unknown
+func DetectHostType(host string) string {
+	host = strings.ToLower(host)
+	for _, h := range knownHosts {
+		if strings.Contains(host, h.pattern) {
+			return h.hostType
+		}
+	}
+	return ""
+}
+
+// Links returns the URL patterns of the repository; the patterns are empty if the host type is unknown
+func (r Repository) Links() Links {
+	preset, ok := Presets[r.HostType]
+	if !ok {
+		return Links{}
+	}
+	base := strings.TrimSuffix(r.WebUrl, "/")
+	return Links{
+		Commit:       strings.ReplaceAll(preset.Commit, "{base}", base),
+		Tag:          strings.ReplaceAll(preset.Tag, "{base}", base),
+		Compare:      strings.ReplaceAll(preset.Compare, "{base}", base),
+		Issue:        strings.ReplaceAll(preset.Issue, "{base}", base),
+		MergeRequest: strings.ReplaceAll(preset.MergeRequest, "{base}", base),
+	}
+}
+
+// ValidateHostType checks if the host type is supported
+func ValidateHostType(hostType string) error {
+	if _, ok := Presets[hostType]; !ok {
+		return fmt.Errorf("%v: hostType=%q, supported=%q", ErrUnknownHostType, hostType, HostTypes())
+	}
+	return nil
+}
+
+// HostTypes returns the supported host types
+func HostTypes() []string {
+	return []string{HostGitHub, HostGitLab, HostBitbucket, HostGitea}
+}
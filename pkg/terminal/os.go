@@ -32,7 +32,7 @@ func GetEnv(key string) (string, error) {
 
 // Shell executes a command in a shell and removes all new lines from the output
 func Shell(cmd string) (string, error) {
-	out, err := execute(cmd)
+	out, err := execute(cmd, "")
 	if err != nil {
 		return string(out), err
 	}
@@ -43,7 +43,7 @@ func Shell(cmd string) (string, error) {
 
 // ShellRaw executes a command in a shell. Similar to Shell but this one doesn't format the output
 func ShellRaw(cmd string) (string, error) {
-	out, err := execute(cmd)
+	out, err := execute(cmd, "")
 	if err != nil {
 		return string(out), err
 	}
@@ -51,9 +51,20 @@ func ShellRaw(cmd string) (string, error) {
 	return string(out), nil
 }
 
-// execute a shell command
-func execute(cmd string) ([]byte, error) {
+// ShellInput executes a command in a shell and writes the input to its standard input. Similar to ShellRaw, the output isn't formatted
+func ShellInput(cmd string, input string) (string, error) {
+	out, err := execute(cmd, input)
+	if err != nil {
+		return string(out), err
+	}
+
+	return string(out), nil
+}
+
+// execute a shell command, the input is written to its standard input
+func execute(cmd string, input string) ([]byte, error) {
 	c := exec.Command(ShellName, "-c", cmd)
+	c.Stdin = strings.NewReader(input)
 	c.Stderr = os.Stderr
 	out, err := c.Output()
 	outAsString := string(out)
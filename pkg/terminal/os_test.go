@@ -120,3 +120,39 @@ func Test_Shell(t *testing.T) {
 		})
 	}
 }
+
+func Test_ShellInput(t *testing.T) {
+	// arrange
+	tables := []struct {
+		cmd   string
+		input string
+
+		want string
+	}{
+		{"cat", "hello kitty\n", "hello kitty\n"},
+		{"tr a-z A-Z", "hello kitty", "HELLO KITTY"},
+		{"wc -l | tr -d ' '", "", "0\n"},
+		{"aaaa", "hello kitty", ErrShellCommand.Error()},
+	}
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.cmd, func(t *testing.T) {
+			out, err := ShellInput(tb.cmd, tb.input)
+
+			// assert
+			got := out
+			if err != nil {
+				got = err.Error()
+			}
+
+			assertCorrectMessage(t, got, tb.want)
+		})
+	}
+}
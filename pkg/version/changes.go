@@ -0,0 +1,128 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/diff"
+	"semtag/pkg/output"
+)
+
+var (
+	ErrApplyChanges = errors.New("unable to apply the file changes; the files already written have been restored")
+)
+
+// Change is a planned update of the contents of a file
+type Change struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// Changes to several files that are applied as a single transaction
+type Changes []Change
+
+/*
+PlanChanges validates all the files and calculates their new contents, without writing anything:
+  - the version is replaced in each file as described by File.Update
+  - a file can be listed more than once (e.g. Chart.yaml:.version and Chart.yaml:.appVersion); the updates are applied one after the other
+  - the files whose contents don't change are not included in the result
+*/
+func PlanChanges(files []File, ver string) (Changes, error) {
+	var paths []string
+	originals := map[string]string{}
+	contents := map[string]string{}
+	for _, f := range files {
+		f.Version = ver
+
+		current, ok := contents[f.Path]
+		if !ok {
+			dat, err := f.Read()
+			if err != nil {
+				return nil, err
+			}
+			current = string(dat)
+			originals[f.Path] = current
+			paths = append(paths, f.Path)
+		}
+
+		newContents, err := f.UpdateContents([]byte(current))
+		if err != nil {
+			return nil, err
+		}
+		contents[f.Path] = newContents
+	}
+
+	var changes Changes
+	for _, p := range paths {
+		if originals[p] == contents[p] {
+			output.Logger().WithField("filePath", p).Info("the file already contains the version")
+			continue
+		}
+		changes = append(changes, Change{Path: p, Old: originals[p], New: contents[p]})
+	}
+	return changes, nil
+}
+
+// Apply writes all the changes; if a write fails, the files that have already been written are restored to their original contents
+func (c Changes) Apply() error {
+	for i, change := range c {
+		f := File{Path: change.Path}
+		err := f.Write(change.New)
+		if err == nil {
+			continue
+		}
+
+		return c[:i].Rollback(err)
+	}
+
+	output.Logger().WithFields(logrus.Fields{
+		"filePaths": c.Paths(),
+	}).Debug("file changes applied")
+	return nil
+}
+
+// Read returns the new contents of a file if it is changed, otherwise its contents on disk
+func (c Changes) Read(path string) ([]byte, error) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].Path == path {
+			return []byte(c[i].New), nil
+		}
+	}
+	return ioutil.ReadFile(path)
+}
+
+// Rollback restores the files of the changes to their original contents after a failure (e.g. a file can't be written or staged) and returns the failure
+func (c Changes) Rollback(cause error) error {
+	for _, written := range c {
+		wf := File{Path: written.Path}
+		if err := wf.Write(written.Old); err != nil {
+			return fmt.Errorf("%v: %v; rollback failed: %v", ErrApplyChanges, cause, err)
+		}
+		output.Logger().WithField("filePath", written.Path).Warn("file restored to its original contents")
+	}
+	return fmt.Errorf("%v: %v", ErrApplyChanges, cause)
+}
+
+// Paths of the changed files
+func (c Changes) Paths() []string {
+	var paths []string
+	for _, change := range c {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// Diff returns the unified diff of all the changes, in the order in which they are applied
+func (c Changes) Diff() string {
+	var b strings.Builder
+	for _, change := range c {
+		b.WriteString(diff.Unified(filepath.ToSlash(filepath.Clean(change.Path)), change.Old, change.New))
+	}
+	return b.String()
+}
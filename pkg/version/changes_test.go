@@ -0,0 +1,102 @@
+package version
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_PlanChanges(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "Chart.yaml")
+	versionFile := filepath.Join(dir, "VERSION")
+	setup := filepath.Join(dir, "setup.py")
+	writeTestFile(t, chart, "version: 1.2.3\nappVersion: 1.2.3\n")
+	writeTestFile(t, versionFile, "2.0.0\n")
+	writeTestFile(t, setup, "setup(version='1.2.3',)\n")
+
+	files := []File{
+		{Path: chart},
+		{Path: chart, VersionPath: ".appVersion"},
+		{Path: versionFile},
+		{Path: setup, VersionFormat: "version='%s',"},
+	}
+
+	// act
+	changes, err := PlanChanges(files, "2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	wantPaths := []string{chart, setup}
+	if !reflect.DeepEqual(changes.Paths(), wantPaths) {
+		t.Errorf("got %v want %v", changes.Paths(), wantPaths)
+	}
+	if changes[0].New != "version: 2.0.0\nappVersion: 2.0.0\n" {
+		t.Errorf("got %q want both chart versions to be updated", changes[0].New)
+	}
+	if d := changes.Diff(); !strings.Contains(d, "-appVersion: 1.2.3\n+version: 2.0.0\n+appVersion: 2.0.0\n") {
+		t.Errorf("got diff:\n%s\nwant it to contain the appVersion change", d)
+	}
+	if got := readTestFile(t, chart); got != "version: 1.2.3\nappVersion: 1.2.3\n" {
+		t.Errorf("got %q: the file must not be written when planning the changes", got)
+	}
+}
+
+func Test_PlanChangesNegative(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "package.json")
+	writeTestFile(t, pkg, `{"name": "app"}`)
+
+	// act
+	_, err := PlanChanges([]File{{Path: pkg}}, "2.0.0")
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), ErrVersionPathNotFound.Error()) {
+		t.Errorf("got %v want %q", err, ErrVersionPathNotFound)
+	}
+}
+
+func Test_ApplyRollback(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	writeTestFile(t, first, "1.2.3")
+	changes := Changes{
+		{Path: first, Old: "1.2.3", New: "2.0.0"},
+		{Path: filepath.Join(dir, "missing", "second.txt"), Old: "1.2.3", New: "2.0.0"},
+	}
+
+	// act
+	err := changes.Apply()
+
+	// assert
+	if err == nil || !strings.Contains(err.Error(), ErrApplyChanges.Error()) {
+		t.Errorf("got %v want %q", err, ErrApplyChanges)
+	}
+	if got := readTestFile(t, first); got != "1.2.3" {
+		t.Errorf("got %q want %q: the file must be restored", got, "1.2.3")
+	}
+}
+
+func writeTestFile(t *testing.T, path string, contents string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	dat, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(dat)
+}
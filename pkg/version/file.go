@@ -1,9 +1,12 @@
 package version
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -12,55 +15,135 @@ import (
 	"semtag/pkg/output"
 )
 
-var ErrNoMatchFoundVersionFormat = errors.New("no match found for version format")
+const (
+	defaultFileMode = 0644
+)
+
+var (
+	utf8Bom = []byte{0xEF, 0xBB, 0xBF}
+
+	ErrNoMatchFoundVersionFormat = errors.New("no match found for version format")
+	ErrParseFileSpec             = errors.New("file spec can't be parsed")
+	ErrInvalidVersionFormat      = errors.New("the version format must contain %s exactly once")
+)
 
 type File struct {
-	Path          string
+	Path string
+	// VersionFormat is the pattern of the version string in the file (e.g. version='%s',)
 	VersionFormat string
-	Version       string
+	// VersionPath addresses the version value in a structured file (e.g. .version in package.json, package.version in Cargo.toml, /project/version in pom.xml)
+	VersionPath string
+	// Format of the structured file; if empty it is detected from the file name
+	Format  string
+	Version string
 }
 
-// Read data from file
+// Read data from file; the byte order mark and the Windows line endings (CRLF) are removed, so that the data can be processed the same way for all files
 func (f *File) Read() ([]byte, error) {
 	dat, err := ioutil.ReadFile(f.Path)
 	if err != nil {
 		return []byte{}, fmt.Errorf("failed to read file %q: %v", f.Path, err)
 	}
+	style := detectTextStyle(dat)
+	dat = style.normalize(dat)
 	output.Logger().WithFields(logrus.Fields{
-		"filePath": f.Path,
+		"filePath":    f.Path,
+		"fileHasBom":  style.bom,
+		"fileHasCrlf": style.crlf,
 	}).Debug("read from file successfully")
 	return dat, nil
 }
 
-// Write data to file
+/*
+Write data to file atomically: the data is written to a temporary file in the same directory, which then replaces the file
+  - the mode and the ownership of the existing file are preserved
+  - the byte order mark and the Windows line endings (CRLF) of the existing file are restored
+  - if the file is a symbolic link, the target of the link is replaced
+*/
 func (f *File) Write(data string) error {
-	newContents := []byte(data)
-	err := ioutil.WriteFile(f.Path, newContents, 0)
-	if err != nil {
+	path := f.Path
+	if target, err := filepath.EvalSymlinks(f.Path); err == nil {
+		path = target
+	}
+	mode := os.FileMode(defaultFileMode)
+	var style textStyle
+	info, err := os.Stat(path)
+	if err == nil {
+		mode = info.Mode().Perm()
+		if existing, err := ioutil.ReadFile(path); err == nil {
+			style = detectTextStyle(existing)
+		}
+	}
+	newContents := style.restore(style.normalize([]byte(data)))
+
+	if err := writeAtomic(path, newContents, mode, info); err != nil {
 		return fmt.Errorf("failed to write data=%q to file=%q: %v", data, f.Path, err)
 	}
 	output.Logger().WithFields(logrus.Fields{
-		"filePath": f.Path,
-		"fileData": data,
+		"filePath":    f.Path,
+		"fileData":    data,
+		"fileMode":    mode.String(),
+		"fileHasBom":  style.bom,
+		"fileHasCrlf": style.crlf,
 	}).Debug("write to file successfully")
 	return nil
 }
 
+// writeAtomic writes the data to a temporary file in the same directory and renames it to the file path; the existing file (if any) is never left partially written
+func writeAtomic(path string, data []byte, mode os.FileMode, existing os.FileInfo) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".semtag-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return err
+	}
+	if existing != nil {
+		if err := chownLike(tmpPath, existing); err != nil {
+			output.Logger().WithFields(logrus.Fields{
+				"filePath": path,
+				"err":      err,
+			}).Warn("unable to preserve the ownership of the file")
+		}
+	}
+	return os.Rename(tmpPath, path)
+}
+
 // ReplaceSubstring in file
 func (f *File) ReplaceSubstring() (string, error) {
-	toFind := strings.Replace(f.VersionFormat, "%s", ".*", 1)
-	re := regexp.MustCompile(fmt.Sprintf("%s", toFind))
 	dat, err := f.Read()
 	if err != nil {
 		return "", err
 	}
-	match := re.FindStringSubmatch(string(dat))
-	if len(match) != 1 {
+	return f.replaceSubstring(dat)
+}
+
+// replaceSubstring replaces the version in every substring that matches the version format (see versionRegex)
+func (f *File) replaceSubstring(dat []byte) (string, error) {
+	re, err := f.versionRegex()
+	if err != nil {
+		return "", err
+	}
+	if !re.Match(dat) {
 		return "", fmt.Errorf("%v: file=%q, versionFormat=%q", ErrNoMatchFoundVersionFormat, f.Path, f.VersionFormat)
 	}
 
-	newVersionLine := fmt.Sprintf(f.VersionFormat, f.Version)
-	newContents := strings.Replace(string(dat), match[0], newVersionLine, -1)
+	newVersionLine := strings.Replace(f.VersionFormat, "%s", f.Version, 1)
+	newContents := re.ReplaceAllLiteralString(string(dat), newVersionLine)
 
 	output.Logger().WithFields(logrus.Fields{
 		"filePath":           f.Path,
@@ -68,3 +151,230 @@ func (f *File) ReplaceSubstring() (string, error) {
 	}).Info("string replaced in file")
 	return newContents, nil
 }
+
+/*
+Update returns the new contents of the file with the version replaced:
+  - if a version path is set, the value found at the path is replaced
+  - if a version format is set, the substring that matches the format is replaced
+  - otherwise the built-in preset of the file is used (e.g. .version for package.json)
+*/
+func (f *File) Update() (string, error) {
+	dat, err := f.Read()
+	if err != nil {
+		return "", err
+	}
+	return f.UpdateContents(dat)
+}
+
+// UpdateContents is similar to Update, but it replaces the version in the provided contents of the file instead of reading the file
+func (f *File) UpdateContents(dat []byte) (string, error) {
+	if f.VersionFormat != "" && f.VersionPath == "" {
+		return f.replaceSubstring(dat)
+	}
+	if f.VersionPath == "" {
+		if err := f.usePreset(dat); err != nil {
+			return "", err
+		}
+	}
+	return f.replaceValue(dat)
+}
+
+// usePreset sets the format and the version path from the built-in preset of the file; the first version path of the preset found in the file is used
+func (f *File) usePreset(dat []byte) error {
+	preset, ok := FindPreset(f.Path)
+	if !ok {
+		return fmt.Errorf("%v: file=%q", ErrNoPresetFound, f.Path)
+	}
+	for _, p := range preset.VersionPaths {
+		if _, _, err := findValue(dat, preset.Format, p); err == nil {
+			f.Format = preset.Format
+			f.VersionPath = p
+			return nil
+		}
+	}
+	return fmt.Errorf("%v: file=%q, versionPaths=%q", ErrVersionPathNotFound, f.Path, preset.VersionPaths)
+}
+
+// ReplaceValue in a structured file (JSON, YAML, TOML, XML); only the version value is replaced, so that the formatting and the comments of the file are preserved
+func (f *File) ReplaceValue() (string, error) {
+	dat, err := f.Read()
+	if err != nil {
+		return "", err
+	}
+	return f.replaceValue(dat)
+}
+
+func (f *File) replaceValue(dat []byte) (string, error) {
+	start, end, err := f.locateValue(dat)
+	if err != nil {
+		return "", err
+	}
+	newContents := string(dat[:start]) + f.Version + string(dat[end:])
+
+	output.Logger().WithFields(logrus.Fields{
+		"filePath":        f.Path,
+		"fileVersionPath": f.VersionPath,
+		"fileOldVersion":  string(dat[start:end]),
+		"fileNewVersion":  f.Version,
+	}).Info("value replaced in file")
+	return newContents, nil
+}
+
+// locateValue returns the offsets of the value found at the version path; the format is detected from the file name if it isn't set
+func (f *File) locateValue(dat []byte) (int, int, error) {
+	if f.Format == "" {
+		format, err := DetectFormat(f.Path)
+		if err != nil {
+			return 0, 0, err
+		}
+		f.Format = format
+	}
+
+	start, end, err := findValue(dat, f.Format, f.VersionPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%v: file=%q", err, f.Path)
+	}
+	return start, end, nil
+}
+
+// ReadVersion returns the version currently found in the file, using the same version path, version format or built-in preset as Update
+func (f *File) ReadVersion() (string, error) {
+	dat, err := f.Read()
+	if err != nil {
+		return "", err
+	}
+
+	if f.VersionFormat == "" || f.VersionPath != "" {
+		if f.VersionPath == "" {
+			if err := f.usePreset(dat); err != nil {
+				return "", err
+			}
+		}
+		start, end, err := f.locateValue(dat)
+		if err != nil {
+			return "", err
+		}
+		return string(dat[start:end]), nil
+	}
+
+	re, err := f.versionRegex()
+	if err != nil {
+		return "", err
+	}
+	match := re.FindStringSubmatch(string(dat))
+	if len(match) != 2 {
+		return "", fmt.Errorf("%v: file=%q, versionFormat=%q", ErrNoMatchFoundVersionFormat, f.Path, f.VersionFormat)
+	}
+	return match[1], nil
+}
+
+// versionRegex returns the regular expression of the version format, used both to read and to replace the version: the version is captured between the literal parts of the format (e.g. version='%s', -> version='(.*?)',); without a literal suffix, the version ends at the first blank character
+func (f *File) versionRegex() (*regexp.Regexp, error) {
+	parts := strings.Split(f.VersionFormat, "%s")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%v: file=%q, versionFormat=%q", ErrInvalidVersionFormat, f.Path, f.VersionFormat)
+	}
+	capture := `(\S+)`
+	if parts[1] != "" {
+		capture = `(.*?)`
+	}
+	re, err := regexp.Compile(regexp.QuoteMeta(parts[0]) + capture + regexp.QuoteMeta(parts[1]))
+	if err != nil {
+		return nil, fmt.Errorf("%v: file=%q, versionFormat=%q: %v", ErrInvalidVersionFormat, f.Path, f.VersionFormat, err)
+	}
+	return re, nil
+}
+
+/*
+Files is a list of version files. It can be used as a repeatable command line flag; each value is a file spec with one of the formats:
+  - <file> uses the built-in preset of a well-known manifest (e.g. package.json)
+  - <file>:<version path> addresses the version in a structured file (e.g. Chart.yaml:.appVersion)
+  - <file>=<version format> replaces the substring that matches the format (e.g. setup.py=version='%s',)
+*/
+type Files []File
+
+func (fs Files) String() string {
+	var out []string
+	for _, f := range fs {
+		out = append(out, f.Spec())
+	}
+	return strings.Join(out, " ")
+}
+
+func (fs *Files) Set(value string) error {
+	f, err := ParseFileSpec(value)
+	if err != nil {
+		return err
+	}
+	*fs = append(*fs, f)
+	return nil
+}
+
+// ParseFileSpec parses a file spec (see Files); the first ':' or '=' separates the file name from the version path or format
+func ParseFileSpec(spec string) (File, error) {
+	i := strings.IndexAny(spec, ":=")
+	if i < 0 {
+		if _, ok := FindPreset(spec); !ok || strings.TrimSpace(spec) == "" {
+			return File{}, fmt.Errorf("%v: file=%q", ErrNoPresetFound, spec)
+		}
+		return File{Path: spec}, nil
+	}
+
+	f := File{Path: spec[:i]}
+	if f.Path == "" || i == len(spec)-1 {
+		return File{}, fmt.Errorf("%v: %q", ErrParseFileSpec, spec)
+	}
+	if spec[i] == ':' {
+		f.VersionPath = spec[i+1:]
+	} else {
+		f.VersionFormat = spec[i+1:]
+	}
+	return f, nil
+}
+
+// Spec returns the file spec of the file (see Files)
+func (f File) Spec() string {
+	if f.VersionPath != "" {
+		return f.Path + ":" + f.VersionPath
+	}
+	if f.VersionFormat != "" {
+		return f.Path + "=" + f.VersionFormat
+	}
+	return f.Path
+}
+
+// textStyle contains the details of a text file that are restored when the file is written
+type textStyle struct {
+	bom  bool
+	crlf bool
+}
+
+// detectTextStyle checks if the data starts with a UTF-8 byte order mark and if all its lines end with CRLF
+func detectTextStyle(dat []byte) textStyle {
+	crlfCount := bytes.Count(dat, []byte("\r\n"))
+	lfCount := bytes.Count(dat, []byte("\n"))
+	return textStyle{
+		bom:  bytes.HasPrefix(dat, utf8Bom),
+		crlf: crlfCount > 0 && crlfCount == lfCount,
+	}
+}
+
+// normalize removes the byte order mark and, for CRLF files, converts the line endings to LF
+func (s textStyle) normalize(dat []byte) []byte {
+	dat = bytes.TrimPrefix(dat, utf8Bom)
+	if s.crlf {
+		dat = bytes.ReplaceAll(dat, []byte("\r\n"), []byte("\n"))
+	}
+	return dat
+}
+
+// restore adds the byte order mark and converts the line endings to CRLF if the style requires it
+func (s textStyle) restore(dat []byte) []byte {
+	if s.crlf {
+		dat = bytes.ReplaceAll(dat, []byte("\n"), []byte("\r\n"))
+	}
+	if s.bom {
+		dat = append(append([]byte{}, utf8Bom...), dat...)
+	}
+	return dat
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package version
+
+import (
+	"os"
+	"syscall"
+)
+
+// chownLike sets the owner and the group of a file to the ones of an existing file
+func chownLike(path string, existing os.FileInfo) error {
+	stat, ok := existing.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if stat.Uid == uint32(os.Getuid()) && stat.Gid == uint32(os.Getgid()) {
+		return nil
+	}
+	return os.Chown(path, int(stat.Uid), int(stat.Gid))
+}
@@ -0,0 +1,10 @@
+package version
+
+import (
+	"os"
+)
+
+// chownLike is a no-op on Windows: the files inherit the permissions of their directory
+func chownLike(path string, existing os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,182 @@
+package version
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_UpdateWrite(t *testing.T) {
+	// arrange
+	tables := []struct {
+		name     string
+		contents string
+		mode     os.FileMode
+		file     File
+
+		want string
+	}{
+		{"package.json", "{\r\n  \"version\": \"1.2.3\"\r\n}\r\n", 0644, File{}, "{\r\n  \"version\": \"2.0.0\"\r\n}\r\n"},
+		{"VERSION", "\xEF\xBB\xBF1.2.3\n", 0644, File{}, "\xEF\xBB\xBF2.0.0\n"},
+		{"release.sh", "#!/bin/sh\r\nVERSION=1.2.3\r\necho $VERSION\r\n", 0755, File{VersionFormat: "VERSION=%s"}, "#!/bin/sh\r\nVERSION=2.0.0\r\necho $VERSION\r\n"},
+		{"mixed.txt", "a\r\nversion=1.2.3\n", 0600, File{VersionFormat: "version=%s"}, "a\r\nversion=2.0.0\n"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("name=%q, mode=%v", tb.name, tb.mode), func(t *testing.T) {
+			dir := t.TempDir()
+			f := tb.file
+			f.Path = filepath.Join(dir, tb.name)
+			f.Version = "2.0.0"
+			if err := ioutil.WriteFile(f.Path, []byte(tb.contents), tb.mode); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(f.Path, tb.mode); err != nil {
+				t.Fatal(err)
+			}
+
+			newContents, err := f.Update()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = f.Write(newContents)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readTestFile(t, f.Path); got != tb.want {
+				t.Errorf("got %q want %q", got, tb.want)
+			}
+			info, err := os.Stat(f.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runtime.GOOS != "windows" && info.Mode().Perm() != tb.mode {
+				t.Errorf("got mode %v want %v", info.Mode().Perm(), tb.mode)
+			}
+			entries, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("got %d files want 1: the temporary file must be removed", len(entries))
+			}
+		})
+	}
+}
+
+func Test_WriteNewFile(t *testing.T) {
+	// arrange
+	f := File{Path: filepath.Join(t.TempDir(), "VERSION")}
+
+	// act
+	err := f.Write("1.0.0\n")
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestFile(t, f.Path); got != "1.0.0\n" {
+		t.Errorf("got %q want %q", got, "1.0.0\n")
+	}
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != defaultFileMode {
+		t.Errorf("got mode %v want %v", info.Mode().Perm(), os.FileMode(defaultFileMode))
+	}
+}
+
+func Test_VersionFormat(t *testing.T) {
+	// arrange
+	tables := []struct {
+		format   string
+		contents string
+
+		wantVersion  string
+		wantContents string
+		wantErr      error
+	}{
+		{`__version__ = ("%s")`, "__version__ = (\"1.2.3\")\n", "1.2.3", "__version__ = (\"2.0.0\")\n", nil},
+		{"app-%s.tgz", "app-1.2.3xtgz\napp-1.2.3.tgz\n", "1.2.3", "app-1.2.3xtgz\napp-2.0.0.tgz\n", nil},
+		{"VERSION=%s", "VERSION=1.2.3 # pinned\n", "1.2.3", "VERSION=2.0.0 # pinned\n", nil},
+		{"version='%s',", "a(version='1.2.3',)\nb(version='1.2.3',)\n", "1.2.3", "a(version='2.0.0',)\nb(version='2.0.0',)\n", nil},
+		{"version", "version=1.2.3\n", "", "", ErrInvalidVersionFormat},
+		{"%s-%s", "1.2.3-1\n", "", "", ErrInvalidVersionFormat},
+		{"version='%s',", "version=1.2.3\n", "", "", ErrNoMatchFoundVersionFormat},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("format=%q", tb.format), func(t *testing.T) {
+			f := File{Path: filepath.Join(t.TempDir(), "VERSION"), VersionFormat: tb.format, Version: "2.0.0"}
+			if err := ioutil.WriteFile(f.Path, []byte(tb.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			gotVersion, readErr := f.ReadVersion()
+			gotContents, updateErr := f.Update()
+
+			// assert
+			if tb.wantErr != nil {
+				for _, err := range []error{readErr, updateErr} {
+					if err == nil || !strings.Contains(err.Error(), tb.wantErr.Error()) {
+						t.Errorf("got %v want %q", err, tb.wantErr)
+					}
+				}
+				return
+			}
+			if readErr != nil || updateErr != nil {
+				t.Fatalf("got errors %v and %v", readErr, updateErr)
+			}
+			if gotVersion != tb.wantVersion {
+				t.Errorf("got version %q want %q", gotVersion, tb.wantVersion)
+			}
+			if gotContents != tb.wantContents {
+				t.Errorf("got %q want %q", gotContents, tb.wantContents)
+			}
+		})
+	}
+}
+
+func Test_ParseFileSpec(t *testing.T) {
+	// arrange
+	tables := []struct {
+		spec string
+
+		want      File
+		wantError bool
+	}{
+		{"package.json", File{Path: "package.json"}, false},
+		{"deploy/Chart.yaml:.appVersion", File{Path: "deploy/Chart.yaml", VersionPath: ".appVersion"}, false},
+		{"setup.py=version='%s',", File{Path: "setup.py", VersionFormat: "version='%s',"}, false},
+		{"values.yaml=image: app:%s", File{Path: "values.yaml", VersionFormat: "image: app:%s"}, false},
+		{"setup.py", File{}, true},
+		{"Chart.yaml:", File{}, true},
+		{":.version", File{}, true},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(tb.spec, func(t *testing.T) {
+			got, err := ParseFileSpec(tb.spec)
+
+			// assert
+			if (err != nil) != tb.wantError {
+				t.Fatalf("got error %v want error %t", err, tb.wantError)
+			}
+			if !reflect.DeepEqual(got, tb.want) {
+				t.Errorf("got %#v want %#v", got, tb.want)
+			}
+		})
+	}
+}
@@ -3,3 +3,14 @@ package version
 import "semtag/pkg/versionControl"
 
 var GitRepo versionControl.VersionControl = &versionControl.GitRepository{}
+
+// FetchTags syncs the local tags with the remote before the latest version is read; it is disabled by the read-only commands, which must not change the repository
+var FetchTags = true
+
+// fetch syncs the local tags with the remote, unless it is disabled
+func fetch() error {
+	if !FetchTags {
+		return nil
+	}
+	return GitRepo.Fetch()
+}
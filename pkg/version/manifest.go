@@ -0,0 +1,129 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatJson = "json"
+	FormatYaml = "yaml"
+	FormatToml = "toml"
+	FormatXml  = "xml"
+	// FormatText is used for files that contain only the version number (e.g. VERSION)
+	FormatText = "text"
+)
+
+var (
+	ErrUnknownFileFormat   = errors.New("unable to determine the file format")
+	ErrVersionPathNotFound = errors.New("no value found for version path")
+	ErrVersionPathNotValue = errors.New("the version path doesn't point to a string value")
+	ErrNoPresetFound       = errors.New("no version pattern, version path or preset found for file")
+)
+
+// Preset is the built-in location of the version in a well-known manifest file
+type Preset struct {
+	Format string
+	// VersionPaths are tried in order; the first one that exists in the file is used
+	VersionPaths []string
+}
+
+/*
+Presets for well-known manifest files, by file name
+  - a preset updates a single value: e.g. the preset of Chart.yaml updates only the chart version (.version), the version of the application is updated with Chart.yaml:.appVersion
+*/
+var Presets = map[string]Preset{
+	"package.json":   {FormatJson, []string{".version"}},
+	"composer.json":  {FormatJson, []string{".version"}},
+	"manifest.json":  {FormatJson, []string{".version"}},
+	"Chart.yaml":     {FormatYaml, []string{".version"}},
+	"pubspec.yaml":   {FormatYaml, []string{".version"}},
+	"Cargo.toml":     {FormatToml, []string{"package.version", "workspace.package.version"}},
+	"pyproject.toml": {FormatToml, []string{"project.version", "tool.poetry.version"}},
+	"pom.xml":        {FormatXml, []string{"/project/version"}},
+	"VERSION":        {FormatText, []string{""}},
+	"VERSION.txt":    {FormatText, []string{""}},
+}
+
+// valueFinder returns the start and end offsets of the (unquoted) string value found at the path
+type valueFinder func(data []byte, path []string) (start int, end int, err error)
+
+var finders = map[string]valueFinder{
+	FormatJson: findJsonValue,
+	FormatYaml: findYamlValue,
+	FormatToml: findTomlValue,
+	FormatXml:  findXmlValue,
+	FormatText: findTextValue,
+}
+
+// DetectFormat returns the format of a file based on its file name or extension
+func DetectFormat(filePath string) (string, error) {
+	if preset, ok := Presets[filepath.Base(filePath)]; ok {
+		return preset.Format, nil
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return FormatJson, nil
+	case ".yaml", ".yml":
+		return FormatYaml, nil
+	case ".toml":
+		return FormatToml, nil
+	case ".xml", ".pom", ".csproj", ".props", ".nuspec":
+		return FormatXml, nil
+	case ".txt":
+		return FormatText, nil
+	}
+	return "", fmt.Errorf("%v: file=%q", ErrUnknownFileFormat, filePath)
+}
+
+// FindPreset returns the built-in preset for a file, if the file is a well-known manifest
+func FindPreset(filePath string) (Preset, bool) {
+	preset, ok := Presets[filepath.Base(filePath)]
+	return preset, ok
+}
+
+/*
+SplitVersionPath splits a version path into its keys. The following notations are supported:
+  - jq-like paths for JSON and YAML (e.g. .version, .image.tag)
+  - dotted keys for TOML (e.g. package.version)
+  - XPath-like paths for XML (e.g. /project/version)
+*/
+func SplitVersionPath(versionPath string) []string {
+	sep := "."
+	if strings.HasPrefix(versionPath, "/") {
+		sep = "/"
+	}
+	var keys []string
+	for _, k := range strings.Split(strings.Trim(versionPath, sep), sep) {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// findValue locates the version value in the data of a structured file
+func findValue(data []byte, format string, versionPath string) (int, int, error) {
+	find, ok := finders[format]
+	if !ok {
+		return 0, 0, fmt.Errorf("%v: format=%q", ErrUnknownFileFormat, format)
+	}
+	start, end, err := find(data, SplitVersionPath(versionPath))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%v: format=%q, versionPath=%q", err, format, versionPath)
+	}
+	return start, end, nil
+}
+
+// findTextValue returns the whole contents of the file, without the leading and trailing white space
+func findTextValue(data []byte, _ []string) (int, int, error) {
+	s := string(data)
+	start := len(s) - len(strings.TrimLeft(s, " \t\r\n"))
+	end := len(strings.TrimRight(s, " \t\r\n"))
+	if end <= start {
+		return 0, 0, ErrVersionPathNotFound
+	}
+	return start, end, nil
+}
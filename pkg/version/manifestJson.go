@@ -0,0 +1,190 @@
+package version
+
+import (
+	"errors"
+	"strconv"
+)
+
+var errInvalidJson = errors.New("invalid JSON")
+
+// jsonScanner walks a JSON document and records the location of the value found at a path, without decoding or re-encoding the document
+type jsonScanner struct {
+	data []byte
+	pos  int
+
+	path       []string
+	valueStart int
+	valueEnd   int
+	found      bool
+}
+
+// findJsonValue returns the offsets of the string value found at the path (e.g. [version] or [packages 0 version]), without the quotes
+func findJsonValue(data []byte, path []string) (int, int, error) {
+	s := &jsonScanner{data: data, path: path}
+	if err := s.value(0); err != nil {
+		return 0, 0, err
+	}
+	if !s.found {
+		return 0, 0, ErrVersionPathNotFound
+	}
+	if s.valueEnd < 0 {
+		return 0, 0, ErrVersionPathNotValue
+	}
+	return s.valueStart, s.valueEnd, nil
+}
+
+// value scans a JSON value; depth is the number of path keys matched so far
+func (s *jsonScanner) value(depth int) error {
+	s.skipSpace()
+	if s.pos >= len(s.data) {
+		return errInvalidJson
+	}
+	isTarget := depth == len(s.path) && !s.found
+
+	switch s.data[s.pos] {
+	case '{':
+		if isTarget {
+			s.markNotString()
+		}
+		return s.object(depth)
+	case '[':
+		if isTarget {
+			s.markNotString()
+		}
+		return s.array(depth)
+	case '"':
+		start := s.pos + 1
+		if _, err := s.str(); err != nil {
+			return err
+		}
+		if isTarget {
+			s.found = true
+			s.valueStart, s.valueEnd = start, s.pos-1
+		}
+		return nil
+	default:
+		if isTarget {
+			s.markNotString()
+		}
+		start := s.pos
+		for s.pos < len(s.data) && !isJsonDelimiter(s.data[s.pos]) {
+			s.pos++
+		}
+		// a delimiter where a value is expected (e.g. [}]) is invalid
+		if s.pos == start {
+			return errInvalidJson
+		}
+		return nil
+	}
+}
+
+func (s *jsonScanner) object(depth int) error {
+	s.pos++ // {
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.data) {
+			return errInvalidJson
+		}
+		if s.data[s.pos] == '}' {
+			s.pos++
+			return nil
+		}
+		if s.data[s.pos] == ',' {
+			s.pos++
+			continue
+		}
+
+		key, err := s.str()
+		if err != nil {
+			return err
+		}
+		s.skipSpace()
+		if s.pos >= len(s.data) || s.data[s.pos] != ':' {
+			return errInvalidJson
+		}
+		s.pos++
+
+		if err := s.value(s.childDepth(depth, key)); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *jsonScanner) array(depth int) error {
+	s.pos++ // [
+	for i := 0; ; {
+		s.skipSpace()
+		if s.pos >= len(s.data) {
+			return errInvalidJson
+		}
+		if s.data[s.pos] == ']' {
+			s.pos++
+			return nil
+		}
+		if s.data[s.pos] == '}' {
+			return errInvalidJson
+		}
+		if s.data[s.pos] == ',' {
+			s.pos++
+			i++
+			continue
+		}
+
+		if err := s.value(s.childDepth(depth, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+}
+
+// childDepth returns the depth of a child value: the path is matched further only if the parent matched the path so far
+func (s *jsonScanner) childDepth(depth int, key string) int {
+	if depth >= 0 && depth < len(s.path) && s.path[depth] == key {
+		return depth + 1
+	}
+	return -1
+}
+
+// str scans a JSON string and returns its raw contents
+func (s *jsonScanner) str() (string, error) {
+	if s.pos >= len(s.data) || s.data[s.pos] != '"' {
+		return "", errInvalidJson
+	}
+	start := s.pos
+	s.pos++
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return string(s.data[start+1 : s.pos-1]), nil
+		default:
+			s.pos++
+		}
+	}
+	return "", errInvalidJson
+}
+
+func (s *jsonScanner) markNotString() {
+	s.found = true
+	s.valueEnd = -1
+}
+
+func (s *jsonScanner) skipSpace() {
+	for s.pos < len(s.data) {
+		switch s.data[s.pos] {
+		case ' ', '\t', '\r', '\n':
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+func isJsonDelimiter(c byte) bool {
+	switch c {
+	case ',', '}', ']', ' ', '\t', '\r', '\n':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package version
+
+import (
+	"strings"
+)
+
+/*
+findTomlValue returns the offsets of the string value found at the path (e.g. [package version]), without the quotes. The document is scanned line by line:
+  - table headers (e.g. [package], [tool.poetry]) set the table of the following keys; keys in arrays of tables (e.g. [[bin]]) are not addressable
+  - keys can be bare, quoted or dotted (e.g. version = "1.0.0", package.version = "1.0.0")
+  - only single-line basic or literal strings are supported as the version value
+  - the lines of the other multi-line values (multi-line strings, arrays and inline tables) are skipped, so that their contents are never read as keys
+*/
+func findTomlValue(data []byte, path []string) (int, int, error) {
+	var table []string
+	inArrayTable := false
+	// the state of the multi-line value that continues on the next line: the delimiter of an open multi-line string and the depth of the open arrays and inline tables
+	multiline, depth := "", 0
+
+	offset := 0
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		lineStart := offset
+		offset += len(line)
+
+		if multiline != "" || depth > 0 {
+			multiline, depth = scanTomlValue(line, multiline, depth)
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "[["):
+			inArrayTable = true
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			table = splitTomlKey(trimmed[1:end])
+			inArrayTable = false
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 || inArrayTable {
+			continue
+		}
+		key := append(append([]string{}, table...), splitTomlKey(line[:eq])...)
+		if !equalKeys(key, path) {
+			multiline, depth = scanTomlValue(line[eq+1:], "", 0)
+			continue
+		}
+
+		valueOffset := eq + 1 + len(line[eq+1:]) - len(strings.TrimLeft(line[eq+1:], " \t"))
+		value := line[valueOffset:]
+		if len(value) == 0 || (value[0] != '"' && value[0] != '\'') || strings.HasPrefix(value, `"""`) || strings.HasPrefix(value, "'''") {
+			return 0, 0, ErrVersionPathNotValue
+		}
+		end := closingQuote(value)
+		if end < 0 {
+			return 0, 0, ErrVersionPathNotValue
+		}
+		start := lineStart + valueOffset + 1
+		return start, start + end - 1, nil
+	}
+	return 0, 0, ErrVersionPathNotFound
+}
+
+// scanTomlValue scans (a line of) a value and returns the delimiter of the multi-line string and the depth of the arrays and inline tables that are still open at its end
+func scanTomlValue(s string, multiline string, depth int) (string, int) {
+	for i := 0; i < len(s); i++ {
+		if multiline != "" {
+			end := strings.Index(s[i:], multiline)
+			if end < 0 {
+				return multiline, depth
+			}
+			i += end + len(multiline) - 1
+			multiline = ""
+			continue
+		}
+		switch c := s[i]; {
+		case strings.HasPrefix(s[i:], `"""`) || strings.HasPrefix(s[i:], "'''"):
+			multiline = s[i : i+3]
+			i += 2
+		case c == '"' || c == '\'':
+			end := closingQuote(s[i:])
+			if end < 0 {
+				return "", depth
+			}
+			i += end
+		case c == '#':
+			return "", depth
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return multiline, depth
+}
+
+// splitTomlKey splits a dotted TOML key and removes the quotes and the white space around its parts
+func splitTomlKey(key string) []string {
+	var parts []string
+	for _, p := range strings.Split(key, ".") {
+		p = strings.TrimSpace(p)
+		p = strings.Trim(p, `"'`)
+		parts = append(parts, p)
+	}
+	return parts
+}
+
+// closingQuote returns the index of the quote that closes the string starting at index 0
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+		if s[i] == '\n' {
+			return -1
+		}
+	}
+	return -1
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
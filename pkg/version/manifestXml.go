@@ -0,0 +1,52 @@
+package version
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// findXmlValue returns the offsets of the text of the first element found at the path (e.g. [project version]); the namespaces of the elements are ignored
+func findXmlValue(data []byte, path []string) (int, int, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+
+	var stack []string
+	for {
+		before := int(d.InputOffset())
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return 0, 0, ErrVersionPathNotFound
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid XML: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+		case xml.EndElement:
+			if equalKeys(stack, path) {
+				// the element is empty
+				return 0, 0, ErrVersionPathNotValue
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if !equalKeys(stack, path) {
+				continue
+			}
+			after := int(d.InputOffset())
+			text := string(data[before:after])
+			trimmed := strings.TrimSpace(text)
+			if trimmed == "" || strings.ContainsAny(trimmed, "&<") {
+				return 0, 0, ErrVersionPathNotValue
+			}
+			start := before + strings.Index(text, trimmed)
+			return start, start + len(trimmed), nil
+		}
+	}
+}
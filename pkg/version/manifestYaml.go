@@ -0,0 +1,84 @@
+package version
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// findYamlValue returns the offsets of the scalar value found at the path (e.g. [appVersion] or [image tag]), without the quotes
+func findYamlValue(data []byte, path []string) (int, int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, 0, fmt.Errorf("invalid YAML: %v", err)
+	}
+	if len(doc.Content) == 0 {
+		return 0, 0, ErrVersionPathNotFound
+	}
+
+	node := doc.Content[0]
+	for _, key := range path {
+		node = yamlChild(node, key)
+		if node == nil {
+			return 0, 0, ErrVersionPathNotFound
+		}
+	}
+	if node.Kind != yaml.ScalarNode || node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return 0, 0, ErrVersionPathNotValue
+	}
+
+	start := lineColumnOffset(data, node.Line, node.Column)
+	if start < 0 {
+		return 0, 0, ErrVersionPathNotFound
+	}
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		start++
+	}
+	end := start + len(node.Value)
+	if end > len(data) || string(data[start:end]) != node.Value {
+		return 0, 0, ErrVersionPathNotValue
+	}
+	return start, end, nil
+}
+
+// yamlChild returns the value of a mapping key or of a sequence index
+func yamlChild(node *yaml.Node, key string) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		i, err := strconv.Atoi(key)
+		if err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// lineColumnOffset converts a 1-based line and column to a byte offset
+func lineColumnOffset(data []byte, line, column int) int {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(data[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	// the column is counted in characters: skip the multi-byte characters that precede the value
+	for c := 1; c < column && offset < len(data); c++ {
+		_, size := utf8.DecodeRune(data[offset:])
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,167 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_FindValue(t *testing.T) {
+	// arrange
+	tables := []struct {
+		format      string
+		versionPath string
+		contents    string
+
+		want string
+	}{
+		{FormatJson, ".version", `{
+  "name": "app",
+  "dependencies": {"version": "9.9.9"},
+  "version": "1.2.3",
+  "private": true
+}`, `{
+  "name": "app",
+  "dependencies": {"version": "9.9.9"},
+  "version": "2.0.0",
+  "private": true
+}`},
+		{FormatJson, ".packages.1.version", `{"packages": [{"version": "1.0.0"}, {"version": "1.2.3"}]}`,
+			`{"packages": [{"version": "1.0.0"}, {"version": "2.0.0"}]}`},
+		{FormatYaml, ".appVersion", `# the chart
+apiVersion: v2
+version: 0.1.0 # the chart version
+appVersion: "1.2.3" # the app version
+`, `# the chart
+apiVersion: v2
+version: 0.1.0 # the chart version
+appVersion: "2.0.0" # the app version
+`},
+		{FormatYaml, ".image.tag", `image:
+  repository: nginx
+  tag: 1.2.3
+`, `image:
+  repository: nginx
+  tag: 2.0.0
+`},
+		{FormatToml, "package.version", `[package]
+name = "app"
+# the crate version
+version = "1.2.3"
+
+[dependencies]
+serde = { version = "1.0" }
+`, `[package]
+name = "app"
+# the crate version
+version = "2.0.0"
+
+[dependencies]
+serde = { version = "1.0" }
+`},
+		{FormatToml, "package.version", `[package]
+description = """
+version = "9.9.9"
+"""
+keywords = [
+  "version = 8.8.8",
+]
+version = "1.2.3"
+`, `[package]
+description = """
+version = "9.9.9"
+"""
+keywords = [
+  "version = 8.8.8",
+]
+version = "2.0.0"
+`},
+		{FormatToml, "tool.poetry.version", `[tool.poetry]
+version = '1.2.3'
+`, `[tool.poetry]
+version = '2.0.0'
+`},
+		{FormatXml, "/project/version", `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <version>9.9.9</version>
+  </parent>
+  <!-- the project version -->
+  <version> 1.2.3 </version>
+</project>
+`, `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <version>9.9.9</version>
+  </parent>
+  <!-- the project version -->
+  <version> 2.0.0 </version>
+</project>
+`},
+		{FormatText, "", "1.2.3\n", "2.0.0\n"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("format=%q, versionPath=%q", tb.format, tb.versionPath), func(t *testing.T) {
+			start, end, err := findValue([]byte(tb.contents), tb.format, tb.versionPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := tb.contents[:start] + "2.0.0" + tb.contents[end:]
+
+			// assert
+			if tb.contents[start:end] != "1.2.3" {
+				t.Errorf("got value %q want %q", tb.contents[start:end], "1.2.3")
+			}
+			if got != tb.want {
+				t.Errorf("got %q want %q", got, tb.want)
+			}
+		})
+	}
+}
+
+func Test_FindValueNegative(t *testing.T) {
+	// arrange
+	tables := []struct {
+		format      string
+		versionPath string
+		contents    string
+
+		want string
+	}{
+		{FormatJson, ".version", `{"name": "app"}`, ErrVersionPathNotFound.Error()},
+		{FormatJson, ".version", `{"version": {"major": 1}}`, ErrVersionPathNotValue.Error()},
+		{FormatJson, ".version", `{"a": [}], "version": "1.0.0"}`, errInvalidJson.Error()},
+		{FormatJson, ".version", `{"a": {]}, "version": "1.0.0"}`, errInvalidJson.Error()},
+		{FormatJson, ".version", `{"a": [1, }], "version": "1.0.0"}`, errInvalidJson.Error()},
+		{FormatJson, ".version", `{"a": [1, 2`, errInvalidJson.Error()},
+		{FormatJson, ".version", `{"version": "1.0.0"`, errInvalidJson.Error()},
+		{FormatJson, ".version", `{"version": "1.0.0`, errInvalidJson.Error()},
+		{FormatYaml, ".version", `name: app`, ErrVersionPathNotFound.Error()},
+		{FormatToml, "package.version", "[dependencies]\nversion = \"1.0.0\"\n", ErrVersionPathNotFound.Error()},
+		{FormatToml, "package.version", "[package]\nversion = \"\"\"\n1.0.0\n\"\"\"\n", ErrVersionPathNotValue.Error()},
+		{FormatToml, "package.version", "[package]\nnotes = '''\nversion = \"1.0.0\"\n'''\n", ErrVersionPathNotFound.Error()},
+		{FormatXml, "/project/version", `<project><parent><version>1.0</version></parent></project>`, ErrVersionPathNotFound.Error()},
+		{"ini", "version", `version=1.0.0`, ErrUnknownFileFormat.Error()},
+	}
+	assertCorrectMessage := func(t *testing.T, got, want string) {
+		t.Helper()
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("format=%q, versionPath=%q", tb.format, tb.versionPath), func(t *testing.T) {
+			_, _, err := findValue([]byte(tb.contents), tb.format, tb.versionPath)
+
+			// assert
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tb.want)
+			}
+			assertCorrectMessage(t, err.Error(), tb.want)
+		})
+	}
+}
@@ -34,8 +34,7 @@ func (s *Scope) Parse(scopeToParse string) error {
 		s.Id = MINOR
 	case "patch":
 		s.Id = PATCH
-	case "none":
-	case "":
+	case "none", "":
 		s.Id = NONE
 	default:
 		return fmt.Errorf("%v: %s", ErrParseScopeName, scopeToParse)
@@ -58,3 +57,21 @@ func (s Scope) String() string {
 	}
 	return "none"
 }
+
+// IsGreaterThan checks if the scope increments a more significant version number than another scope (MAJOR > MINOR > PATCH > NONE)
+func (s Scope) IsGreaterThan(other Scope) bool {
+	return s.rank() > other.rank()
+}
+
+// rank orders the scopes by the significance of the version number they increment
+func (s Scope) rank() int {
+	switch s.Id {
+	case MAJOR:
+		return 3
+	case MINOR:
+		return 2
+	case PATCH:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,210 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/output"
+	"semtag/pkg/terminal"
+)
+
+const (
+	SourceGit  = "git"
+	SourceFile = "file"
+	SourceEnv  = "env"
+	SourceFlag = "flag"
+
+	DefaultEnvVarVersion = "SEMTAG_CURRENT_VERSION"
+)
+
+var (
+	ErrSourceNotAvailable = errors.New("the version source doesn't provide a version")
+	ErrUnknownSource      = errors.New("unknown version source")
+	ErrNoSourceAvailable  = errors.New("none of the version sources provides a version")
+)
+
+// Source of the current version number
+type Source interface {
+	// Name of the source (e.g. git, file)
+	Name() string
+	// Read the current version string; ErrSourceNotAvailable is returned if the source doesn't provide a version
+	Read() (string, error)
+}
+
+// GitTagSource reads the version from the latest git tag that has the prefix and the suffix
+type GitTagSource struct {
+	Prefix string
+	Suffix string
+}
+
+func (s GitTagSource) Name() string {
+	return SourceGit
+}
+
+func (s GitTagSource) Read() (string, error) {
+	if err := fetch(); err != nil {
+		return "", err
+	}
+	tag, err := GitRepo.GetLatestTag(s.Prefix, semanticTaggingRegex, s.Suffix)
+	if err != nil {
+		return "", fmt.Errorf("%v: %s: %v", ErrSourceNotAvailable, s.Name(), err)
+	}
+	return tag, nil
+}
+
+// LatestTag returns the latest version tag that has the prefix and the suffix, without fetching the tags of the remote
+func LatestTag(prefix, suffix string) (string, error) {
+	return GitRepo.GetLatestTag(prefix, semanticTaggingRegex, suffix)
+}
+
+// FileSource reads the version from a file, using the same version path, version format or built-in preset that is used for updating the file
+type FileSource struct {
+	File File
+}
+
+func (s FileSource) Name() string {
+	return SourceFile
+}
+
+func (s FileSource) Read() (string, error) {
+	if s.File.Path == "" {
+		return "", fmt.Errorf("%v: %s: no file configured", ErrSourceNotAvailable, s.Name())
+	}
+	return s.File.ReadVersion()
+}
+
+// EnvSource reads the version from an environment variable
+type EnvSource struct {
+	Key string
+}
+
+func (s EnvSource) Name() string {
+	return SourceEnv
+}
+
+func (s EnvSource) Read() (string, error) {
+	value, err := terminal.GetEnv(s.Key)
+	if err != nil {
+		return "", fmt.Errorf("%v: %s: %v", ErrSourceNotAvailable, s.Name(), err)
+	}
+	return value, nil
+}
+
+// FlagSource uses the version provided explicitly by the user
+type FlagSource struct {
+	Value string
+}
+
+func (s FlagSource) Name() string {
+	return SourceFlag
+}
+
+func (s FlagSource) Read() (string, error) {
+	if s.Value == "" {
+		return "", fmt.Errorf("%v: %s", ErrSourceNotAvailable, s.Name())
+	}
+	return s.Value, nil
+}
+
+// SourceConfig contains the settings of all the version sources
+type SourceConfig struct {
+	Prefix        string
+	Suffix        string
+	File          File
+	EnvVar        string
+	CustomVersion string
+}
+
+// NewSources creates the version sources in the provided order of precedence (e.g. file,git)
+func NewSources(names []string, cfg SourceConfig) ([]Source, error) {
+	var sources []Source
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case SourceGit:
+			sources = append(sources, GitTagSource{Prefix: cfg.Prefix, Suffix: cfg.Suffix})
+		case SourceFile:
+			sources = append(sources, FileSource{File: cfg.File})
+		case SourceEnv:
+			key := cfg.EnvVar
+			if key == "" {
+				key = DefaultEnvVarVersion
+			}
+			sources = append(sources, EnvSource{Key: key})
+		case SourceFlag:
+			sources = append(sources, FlagSource{Value: cfg.CustomVersion})
+		default:
+			return nil, fmt.Errorf("%v: %q, expected one of: %s", ErrUnknownSource, name, strings.Join([]string{SourceFlag, SourceEnv, SourceFile, SourceGit}, ", "))
+		}
+	}
+	return sources, nil
+}
+
+/*
+SetVersionFromSources sets the version number from the first source (in order of precedence) that provides a version
+  - all the other sources that provide a version are checked for consistency: a warning is logged for each source that disagrees with the selected version
+  - if the selected source is git, the hash of the HEAD commit is also set
+  - if no source provides a version, the default version is used
+*/
+func (v *Version) SetVersionFromSources(sources []Source) error {
+	var selected Source
+	var selectedVersion Version
+	for _, s := range sources {
+		raw, err := s.Read()
+		if err != nil {
+			output.Logger().WithFields(logrus.Fields{
+				"versionSource": s.Name(),
+				"err":           err,
+			}).Debug("skipped the version source")
+			continue
+		}
+
+		parsed := Version{Prefix: v.Prefix, Suffix: v.Suffix}
+		if err := parsed.Parse(raw); err != nil {
+			return fmt.Errorf("%v: source=%q", err, s.Name())
+		}
+
+		if selected == nil {
+			selected = s
+			selectedVersion = parsed
+			continue
+		}
+		if !parsed.EqualNumbers(selectedVersion) {
+			output.Logger().WithFields(logrus.Fields{
+				"versionSource":         s.Name(),
+				"version":               parsed.String(),
+				"versionSourceSelected": selected.Name(),
+				"versionSelected":       selectedVersion.String(),
+			}).Warn("the version sources disagree")
+		}
+	}
+
+	if selected == nil {
+		output.Logger().WithFields(logrus.Fields{
+			"defaultVersion": defaultVersion,
+			"err":            ErrNoSourceAvailable,
+		}).Warn("no previous version found, using the default version number")
+		return v.Parse(defaultVersion)
+	}
+
+	v.Major, v.Minor, v.Patch = selectedVersion.Major, selectedVersion.Minor, selectedVersion.Patch
+	if selected.Name() == SourceGit {
+		hash, err := GitRepo.GetHash()
+		if err != nil {
+			return err
+		}
+		v.Hash = hash
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"versionSource": selected.Name(),
+		"version":       v.String(),
+	}).Info("got version number from the version source")
+	return nil
+}
+
+// EqualNumbers checks if two versions have the same major, minor and patch numbers, regardless of their prefix and suffix
+func (v Version) EqualNumbers(other Version) bool {
+	return v.Major == other.Major && v.Minor == other.Minor && v.Patch == other.Patch
+}
@@ -0,0 +1,80 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"semtag/pkg/versionControl"
+)
+
+func Test_SetVersionFromSources(t *testing.T) {
+	// arrange
+	GitRepo = &versionControl.GitRepositoryMock{}
+
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "package.json")
+	setup := filepath.Join(dir, "setup.py")
+	writeTestFile(t, pkg, `{"version": "1.4.0"}`)
+	writeTestFile(t, setup, "setup(\n  version='2.0.1',\n)\n")
+
+	const envVar = "SEMTAG_TEST_CURRENT_VERSION"
+	if err := os.Setenv(envVar, "v3.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(envVar)
+
+	cfg := SourceConfig{
+		Prefix: "v",
+		File:   File{Path: pkg},
+		EnvVar: envVar,
+	}
+	tables := []struct {
+		order         []string
+		customVersion string
+		file          File
+
+		want string
+	}{
+		{[]string{SourceFile, SourceEnv}, "", File{Path: pkg}, "v1.4.0"},
+		{[]string{SourceFile}, "", File{Path: setup, VersionFormat: "version='%s',"}, "v2.0.1"},
+		{[]string{SourceEnv, SourceFile}, "", File{Path: pkg}, "v3.0.0"},
+		{[]string{SourceFlag, SourceEnv}, "5.0.0", File{Path: pkg}, "v5.0.0"},
+		{[]string{SourceFlag, SourceFile}, "", File{Path: pkg}, "v1.4.0"},
+		{[]string{SourceFlag}, "", File{Path: pkg}, "v" + defaultVersion},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("order=%v, customVersion=%q, want=%q", tb.order, tb.customVersion, tb.want), func(t *testing.T) {
+			cfg.CustomVersion = tb.customVersion
+			cfg.File = tb.file
+			sources, err := NewSources(tb.order, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := Version{Prefix: cfg.Prefix}
+
+			err = v.SetVersionFromSources(sources)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != tb.want {
+				t.Errorf("got %q want %q", v.String(), tb.want)
+			}
+		})
+	}
+}
+
+func Test_NewSourcesNegative(t *testing.T) {
+	// act
+	_, err := NewSources([]string{SourceFile, "svn"}, SourceConfig{})
+
+	// assert
+	if err == nil {
+		t.Errorf("expected error %q", ErrUnknownSource)
+	}
+}
@@ -0,0 +1,147 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"semtag/pkg/output"
+)
+
+const (
+	sourceLatestTag = "latest git tag"
+	sourceHeadTag   = "git tag at HEAD"
+)
+
+var (
+	ErrNoVersionTag      = errors.New("no version tag found")
+	ErrVersionsDisagree  = errors.New("the version sources disagree")
+	ErrHeadTagNotLatest  = errors.New("the version tag at HEAD is not the latest version tag")
+	errVersionUnreadable = errors.New("unreadable")
+)
+
+// ReportEntry is the version found in a source during verification
+type ReportEntry struct {
+	Source  string
+	Version string
+	Matches bool
+}
+
+// Report of the verification of the version sources
+type Report struct {
+	// Expected version: the version tag at HEAD if HEAD is tagged, the latest version tag otherwise
+	Expected       string
+	ExpectedSource string
+	Entries        []ReportEntry
+}
+
+/*
+Verify checks if the git tags and the version files agree on the current version
+  - the expected version is the version tag at HEAD if HEAD is tagged, otherwise the latest version tag
+  - the version tag at HEAD (if any) must be the latest version tag
+  - every version file must contain the expected version; the versions are compared without their prefix and suffix
+*/
+func Verify(prefix, suffix string, files []File) (Report, error) {
+	if err := fetch(); err != nil {
+		return Report{}, err
+	}
+	latest, err := GitRepo.GetLatestTag(prefix, semanticTaggingRegex, suffix)
+	if err != nil || latest == "" {
+		return Report{}, fmt.Errorf("%v: prefix=%q, suffix=%q", ErrNoVersionTag, prefix, suffix)
+	}
+	headTags, err := GitRepo.GetTagsAt("HEAD")
+	if err != nil {
+		return Report{}, err
+	}
+
+	r := Report{
+		Expected:       latest,
+		ExpectedSource: sourceLatestTag,
+	}
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(prefix) + semanticTaggingRegex + regexp.QuoteMeta(suffix) + "$")
+	for _, t := range headTags {
+		if re.MatchString(t) {
+			r.Expected = t
+			r.ExpectedSource = sourceHeadTag
+			r.add(prefix, suffix, sourceHeadTag, t)
+			break
+		}
+	}
+	r.add(prefix, suffix, sourceLatestTag, latest)
+
+	for _, f := range files {
+		found, err := f.ReadVersion()
+		if err != nil {
+			output.Logger().WithFields(logrus.Fields{
+				"file": f.Spec(),
+				"err":  err,
+			}).Warn("unable to read the version from the file")
+			found = fmt.Sprintf("<%v: %v>", errVersionUnreadable, err)
+		}
+		r.add(prefix, suffix, f.Spec(), found)
+	}
+
+	output.Logger().WithFields(logrus.Fields{
+		"versionExpected":       r.Expected,
+		"versionSourceExpected": r.ExpectedSource,
+		"versionConsistent":     r.IsConsistent(),
+	}).Info("verified the version sources")
+	return r, nil
+}
+
+// add an entry to the report; the entry matches if it has the same version numbers as the expected version
+func (r *Report) add(prefix, suffix, source, found string) {
+	expected := Version{Prefix: prefix, Suffix: suffix}
+	actual := Version{Prefix: prefix, Suffix: suffix}
+	matches := expected.Parse(r.Expected) == nil && actual.Parse(found) == nil && actual.EqualNumbers(expected)
+	r.Entries = append(r.Entries, ReportEntry{Source: source, Version: found, Matches: matches})
+}
+
+// IsConsistent checks if all the version sources agree
+func (r Report) IsConsistent() bool {
+	for _, e := range r.Entries {
+		if !e.Matches {
+			return false
+		}
+	}
+	return true
+}
+
+// Err returns an error describing the disagreeing sources, or nil if all the version sources agree
+func (r Report) Err() error {
+	var sources []string
+	for _, e := range r.Entries {
+		if !e.Matches {
+			sources = append(sources, e.Source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	if len(sources) == 1 && sources[0] == sourceLatestTag && r.ExpectedSource == sourceHeadTag {
+		return fmt.Errorf("%v: %s=%q", ErrHeadTagNotLatest, sourceHeadTag, r.Expected)
+	}
+	return fmt.Errorf("%v: expected %q (%s) in: %s", ErrVersionsDisagree, r.Expected, r.ExpectedSource, strings.Join(sources, ", "))
+}
+
+/*
+Diff returns the differences between the expected version and the version found in each source:
+  - the lines of the sources that agree start with a space
+  - each source that disagrees has a line with the expected version (starting with -) and a line with the version found (starting with +)
+*/
+func (r Report) Diff() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- expected (%s)\n+++ found\n", r.ExpectedSource)
+	for _, e := range r.Entries {
+		if e.Matches {
+			fmt.Fprintf(&b, "  %s: %s\n", e.Source, e.Version)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", e.Source, r.Expected)
+		fmt.Fprintf(&b, "+ %s: %s\n", e.Source, e.Version)
+	}
+	return b.String()
+}
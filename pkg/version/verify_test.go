@@ -0,0 +1,73 @@
+package version
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"semtag/pkg/versionControl"
+)
+
+// gitRepositoryTagsMock returns predefined tags
+type gitRepositoryTagsMock struct {
+	versionControl.GitRepositoryMock
+	latestTag string
+	headTags  []string
+}
+
+func (g *gitRepositoryTagsMock) GetLatestTag(prefix, baseRegex, suffix string) (string, error) {
+	return g.latestTag, nil
+}
+
+func (g *gitRepositoryTagsMock) GetTagsAt(ref string) ([]string, error) {
+	return g.headTags, nil
+}
+
+func Test_Verify(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "package.json")
+	versionFile := filepath.Join(dir, "VERSION")
+	writeTestFile(t, pkg, `{"version": "1.2.3"}`)
+	writeTestFile(t, versionFile, "1.2.4\n")
+
+	tables := []struct {
+		latestTag string
+		headTags  []string
+		files     []File
+
+		wantConsistent bool
+		wantDiff       string
+	}{
+		{"v1.2.3", []string{"v1.2.3"}, []File{{Path: pkg}}, true, ""},
+		{"v1.2.3", nil, []File{{Path: pkg}}, true, ""},
+		{"v1.2.3", []string{"latest", "v1.2.3"}, []File{{Path: pkg}, {Path: versionFile}}, false,
+			"- " + versionFile + ": v1.2.3\n+ " + versionFile + ": 1.2.4\n"},
+		{"v1.2.4", []string{"v1.2.3"}, []File{{Path: pkg}}, false,
+			"  git tag at HEAD: v1.2.3\n- latest git tag: v1.2.3\n+ latest git tag: v1.2.4\n"},
+	}
+
+	// act
+	for _, tb := range tables {
+		t.Run(fmt.Sprintf("latestTag=%q, headTags=%q", tb.latestTag, tb.headTags), func(t *testing.T) {
+			GitRepo = &gitRepositoryTagsMock{latestTag: tb.latestTag, headTags: tb.headTags}
+
+			r, err := Verify("v", "", tb.files)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.IsConsistent() != tb.wantConsistent {
+				t.Errorf("got consistent=%t want %t:\n%s", r.IsConsistent(), tb.wantConsistent, r.Diff())
+			}
+			if (r.Err() == nil) != tb.wantConsistent {
+				t.Errorf("got error %v want consistent=%t", r.Err(), tb.wantConsistent)
+			}
+			if !strings.Contains(r.Diff(), tb.wantDiff) {
+				t.Errorf("got diff:\n%s\nwant it to contain:\n%s", r.Diff(), tb.wantDiff)
+			}
+		})
+	}
+}
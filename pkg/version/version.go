@@ -54,16 +54,21 @@ func (v *Version) UseCustomVersion(prefix string, customVersion string, suffix s
 
 // SetVersionFromGit retrieves the latest version number based on existing git tags
 func (v *Version) SetVersionFromGit() error {
-	if err := GitRepo.Fetch(); err != nil {
+	return v.SetVersionFromGitOrDefault(defaultVersion)
+}
+
+// SetVersionFromGitOrDefault retrieves the latest version number based on existing git tags; the initial version is used if there is no version tag yet
+func (v *Version) SetVersionFromGitOrDefault(initial string) error {
+	if err := fetch(); err != nil {
 		return err
 	}
 
 	var latest string
 	tag, err := GitRepo.GetLatestTag(v.Prefix, semanticTaggingRegex, v.Suffix)
 	if err != nil {
-		latest = defaultVersion
+		latest = initial
 		output.Logger().WithFields(logrus.Fields{
-			"defaultVersion": defaultVersion,
+			"defaultVersion": initial,
 			"err":            err,
 		}).Warn("no previous version found, using the default version number")
 	} else {
@@ -233,18 +238,7 @@ func (v *Version) SetIncrementScope(scopeAsString string) error {
 		return err
 	}
 
-	s.Id = PATCH
-	if strings.ToLower(scopeAsString) == "major" ||
-		(strings.ToLower(scopeAsString) == "auto" &&
-			strings.Contains(out, "BREAKING CHANGE")) {
-		s.Id = MAJOR
-	} else {
-		if strings.ToLower(scopeAsString) == "minor" ||
-			(strings.ToLower(scopeAsString) == "auto" &&
-				(strings.Contains(out, "feat:") || strings.Contains(out, "feat("))) {
-			s.Id = MINOR
-		}
-	}
+	s = scopeFromLogs(scopeAsString, out)
 
 	v.Scope = s
 	if err := v.Increment(s); err != nil {
@@ -291,3 +285,53 @@ func (v *Version) Increment(s Scope) error {
 
 	return nil
 }
+
+/*
+ScopeForPaths calculates the version Scope based only on the commits that changed the provided paths since the latest version tag:
+  - if no commit changed the paths, then no increment is required
+  - otherwise the same rules as for SetIncrementScope are applied to the commit messages
+*/
+func (v *Version) ScopeForPaths(scopeAsString string, paths []string) (Scope, error) {
+	s := Scope{NONE}
+	if scopeAsString == s.String() || scopeAsString == "" {
+		return s, nil
+	}
+
+	var since string
+	if tag, err := GitRepo.GetLatestTag(v.Prefix, semanticTaggingRegex, v.Suffix); err == nil {
+		since = tag
+	}
+
+	out, err := GitRepo.GetCommitLogsSince(since, paths)
+	if err != nil {
+		return s, err
+	}
+	if strings.TrimSpace(out) != "" {
+		s = scopeFromLogs(scopeAsString, out)
+	}
+
+	output.Logger().WithFields(logrus.Fields{
+		"scopeFromUserInput": scopeAsString,
+		"scope":              s.String(),
+		"versionTagSince":    since,
+		"paths":              paths,
+	}).Debug("calculated the version scope for the changed paths")
+	return s, nil
+}
+
+// scopeFromLogs determines the scope from the user input or, if the user input is AUTO, from the commit messages
+func scopeFromLogs(scopeAsString, logs string) Scope {
+	s := Scope{PATCH}
+	if strings.ToLower(scopeAsString) == "major" ||
+		(strings.ToLower(scopeAsString) == "auto" &&
+			strings.Contains(logs, "BREAKING CHANGE")) {
+		s.Id = MAJOR
+	} else {
+		if strings.ToLower(scopeAsString) == "minor" ||
+			(strings.ToLower(scopeAsString) == "auto" &&
+				(strings.Contains(logs, "feat:") || strings.Contains(logs, "feat("))) {
+			s.Id = MINOR
+		}
+	}
+	return s
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -11,6 +12,12 @@ import (
 	"semtag/pkg/terminal"
 )
 
+const (
+	// fieldSeparator and recordSeparator are the ASCII unit and record separators, used to split the output of git log and git for-each-ref
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
 type GitRepository struct {
 }
 
@@ -112,6 +119,19 @@ func (g *GitRepository) GetTagsHead() (string, error) {
 	return out, nil
 }
 
+func (g *GitRepository) GetTagsAt(ref string) ([]string, error) {
+	out, err := terminal.ShellRaw("git tag --points-at " + ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get tags for %q: %v", ref, err)
+	}
+	tags := strings.Fields(out)
+	output.Logger().WithFields(logrus.Fields{
+		"commit":     ref,
+		"commitTags": tags,
+	}).Debug("found tags for the ref")
+	return tags, nil
+}
+
 func (g *GitRepository) GetHash() (string, error) {
 	const commit = "HEAD"
 	out, err := terminal.Shell("git rev-parse " + commit)
@@ -125,6 +145,18 @@ func (g *GitRepository) GetHash() (string, error) {
 	return out, nil
 }
 
+func (g *GitRepository) GetCommitDate(ref string) (string, error) {
+	out, err := terminal.Shell("git show --no-patch --format=%cI " + ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the commit date for %q: %v", ref, err)
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"commit":     ref,
+		"commitDate": out,
+	}).Debug("found the date of the commit")
+	return out, nil
+}
+
 func (g *GitRepository) GetLatestCommitLogs(count int) (string, error) {
 	out, err := terminal.Shellf("git log %d", count)
 	if err != nil {
@@ -137,6 +169,156 @@ func (g *GitRepository) GetLatestCommitLogs(count int) (string, error) {
 	return out, nil
 }
 
+func (g *GitRepository) GetCommitLogsSince(ref string, paths []string) (string, error) {
+	revisionRange := "HEAD"
+	if ref != "" {
+		revisionRange = ref + "..HEAD"
+	}
+	cmd := "git log " + shellQuote(revisionRange) + " --"
+	for _, p := range paths {
+		if p == DefaultRelevantPath {
+			continue
+		}
+		cmd += " " + shellQuote(p)
+	}
+	out, err := terminal.ShellRaw(cmd)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve the commit logs since %q for paths %q: %v", ref, paths, err)
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"ref":        ref,
+		"paths":      paths,
+		"commitLogs": out,
+	}).Debug("retrieved the commit logs for the paths")
+	return out, nil
+}
+
+func (g *GitRepository) GetTags(regex string) ([]TagInfo, error) {
+	re, err := regexp.Compile(regex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag regex %q: %v", regex, err)
+	}
+	// the dereferenced fields (*) are set for the annotated tags only
+	const format = "%(refname:strip=2)%1f%(objectname)%1f%(*objectname)%1f%(committerdate:iso-strict)%1f%(*committerdate:iso-strict)"
+	out, err := terminal.ShellRaw("git for-each-ref --sort=-v:refname --format=" + shellQuote(format) + " refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the tags: %v", err)
+	}
+
+	var tags []TagInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, fieldSeparator)
+		if len(fields) != 5 || !re.MatchString(fields[0]) {
+			continue
+		}
+		tag := TagInfo{Name: fields[0], Hash: fields[1]}
+		date := fields[3]
+		if fields[2] != "" {
+			tag.Hash, date = fields[2], fields[4]
+		}
+		if tag.Date, err = time.Parse(time.RFC3339, date); err != nil {
+			return nil, fmt.Errorf("unable to parse the date of tag %q: %v", tag.Name, err)
+		}
+		tags = append(tags, tag)
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"tagRegex": regex,
+		"tagCount": len(tags),
+	}).Debug("listed the tags")
+	return tags, nil
+}
+
+func (g *GitRepository) GetCommits(from, to string, paths []string) ([]CommitInfo, error) {
+	const format = "%H%x1f%h%x1f%aN%x1f%aE%x1f%aI%x1f%s%x1f%b%x1e"
+	revisionRange := to
+	if from != "" {
+		revisionRange = from + ".." + to
+	}
+	cmd := fmt.Sprintf("git log --no-merges --reverse --format=%s %s --", shellQuote(format), shellQuote(revisionRange))
+	for _, p := range paths {
+		if p == DefaultRelevantPath {
+			continue
+		}
+		cmd += " " + shellQuote(p)
+	}
+	out, err := terminal.ShellRaw(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve the commits between %q and %q: %v", from, to, err)
+	}
+
+	var commits []CommitInfo
+	for _, record := range strings.Split(out, recordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, fieldSeparator, 7)
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("unable to parse the commit %q", record)
+		}
+		date, err := time.Parse(time.RFC3339, fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the date of commit %q: %v", fields[0], err)
+		}
+		commits = append(commits, CommitInfo{
+			Hash:        fields[0],
+			ShortHash:   fields[1],
+			AuthorName:  fields[2],
+			AuthorEmail: fields[3],
+			Date:        date,
+			Subject:     fields[5],
+			Body:        strings.TrimSpace(fields[6]),
+		})
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"from":        from,
+		"to":          to,
+		"paths":       paths,
+		"commitCount": len(commits),
+	}).Debug("retrieved the commits")
+	return commits, nil
+}
+
+func (g *GitRepository) CheckMailmap(contacts []string) ([]string, error) {
+	if len(contacts) == 0 {
+		return nil, nil
+	}
+	// the contacts are read from the standard input: the whole history doesn't fit on a command line
+	out, err := terminal.ShellInput("git check-mailmap --stdin", strings.Join(contacts, "\n")+"\n")
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve %d contacts with the mailmap: %v", len(contacts), err)
+	}
+	resolved := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(resolved) != len(contacts) {
+		return nil, fmt.Errorf("unable to resolve %d contacts with the mailmap: unexpected output %q", len(contacts), out)
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"contacts":         contacts,
+		"resolvedContacts": resolved,
+	}).Debug("resolved the contacts with the mailmap")
+	return resolved, nil
+}
+
+func (g *GitRepository) GetRemoteUrl(name string) (string, error) {
+	out, err := terminal.Shell("git remote get-url " + shellQuote(name) + " 2> /dev/null")
+	if err != nil {
+		return "", fmt.Errorf("unable to get the URL of the remote %q: %v", name, err)
+	}
+	output.Logger().WithFields(logrus.Fields{
+		"remote":    name,
+		"remoteUrl": out,
+	}).Debug("found the URL of the remote")
+	return out, nil
+}
+
+func (g *GitRepository) GetRootDir() (string, error) {
+	out, err := terminal.Shell("git rev-parse --show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("unable to get the root directory of the working tree: %v", err)
+	}
+	return out, nil
+}
+
 func (g *GitRepository) Fetch() error {
 	_, err := terminal.Shell("git fetch --prune --prune-tags --tags &> /dev/null ")
 	if err != nil {
@@ -145,3 +327,8 @@ func (g *GitRepository) Fetch() error {
 	output.Logger().Debug("successfully fetched changes from remote")
 	return nil
 }
+
+// shellQuote quotes a string for the shell, so that no character (e.g. $, %, `) is interpreted
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -40,14 +40,46 @@ func (g *GitRepositoryMock) GetTagsHead() (string, error) {
 	return "", nil
 }
 
+func (g *GitRepositoryMock) GetTagsAt(ref string) ([]string, error) {
+	return nil, nil
+}
+
 func (g *GitRepositoryMock) GetHash() (string, error) {
 	return "", nil
 }
 
+func (g *GitRepositoryMock) GetCommitDate(ref string) (string, error) {
+	return "", nil
+}
+
 func (g *GitRepositoryMock) GetLatestCommitLogs(count int) (string, error) {
 	return "", nil
 }
 
+func (g *GitRepositoryMock) GetCommitLogsSince(ref string, paths []string) (string, error) {
+	return "", nil
+}
+
+func (g *GitRepositoryMock) GetTags(regex string) ([]TagInfo, error) {
+	return nil, nil
+}
+
+func (g *GitRepositoryMock) GetCommits(from, to string, paths []string) ([]CommitInfo, error) {
+	return nil, nil
+}
+
+func (g *GitRepositoryMock) CheckMailmap(contacts []string) ([]string, error) {
+	return contacts, nil
+}
+
+func (g *GitRepositoryMock) GetRemoteUrl(name string) (string, error) {
+	return "", nil
+}
+
+func (g *GitRepositoryMock) GetRootDir() (string, error) {
+	return ".", nil
+}
+
 func (g *GitRepositoryMock) Fetch() error {
 	return nil
 }
@@ -0,0 +1,25 @@
+package versionControl
+
+import (
+	"time"
+)
+
+// CommitInfo contains the data of a git commit
+type CommitInfo struct {
+	Hash        string
+	ShortHash   string
+	AuthorName  string
+	AuthorEmail string
+	Date        time.Time
+	Subject     string
+	Body        string
+}
+
+// TagInfo contains the data of a git tag
+type TagInfo struct {
+	Name string
+	// Hash of the commit the tag points to
+	Hash string
+	// Date of the commit the tag points to
+	Date time.Time
+}
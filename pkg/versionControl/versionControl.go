@@ -30,12 +30,36 @@ type VersionControl interface {
 	// GetTagsHead retrieves all the tags for the HEAD commit
 	GetTagsHead() (string, error)
 
+	// GetTagsAt retrieves all the tags that point to a ref (e.g. HEAD)
+	GetTagsAt(ref string) ([]string, error)
+
 	// GetHash returns the git has for the HEAD commit
 	GetHash() (string, error)
 
+	// GetCommitDate returns the committer date of a ref (e.g. HEAD) in the strict ISO 8601 format
+	GetCommitDate(ref string) (string, error)
+
 	// GetLatestCommitLogs returns the latest n commit logs
 	GetLatestCommitLogs(count int) (string, error)
 
+	// GetCommitLogsSince returns the commit logs since a ref (or all the commit logs if the ref is empty) for the commits that changed the provided paths
+	GetCommitLogsSince(ref string, paths []string) (string, error)
+
+	// GetTags returns the tags that match the regex, sorted by version from the newest to the oldest
+	GetTags(regex string) ([]TagInfo, error)
+
+	// GetCommits returns the commits reachable from a ref but not from another ref (all the commits reachable from the ref if the other ref is empty), from the oldest to the newest; the merge commits are skipped, and only the commits that changed the provided paths are returned
+	GetCommits(from, to string, paths []string) ([]CommitInfo, error)
+
+	// CheckMailmap returns the canonical names and emails of the contacts (e.g. Jane Doe <jane@example.com>) with the .mailmap of the repository, in the same order
+	CheckMailmap(contacts []string) ([]string, error)
+
+	// GetRemoteUrl returns the URL of a remote (e.g. origin)
+	GetRemoteUrl(name string) (string, error)
+
+	// GetRootDir returns the top-level directory of the working tree
+	GetRootDir() (string, error)
+
 	// Fetch downloads the objects and refs from the remote
 	Fetch() error
 }
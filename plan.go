@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+
+	"semtag/internal"
+	"semtag/pkg/output"
+	"semtag/pkg/release"
+	"semtag/pkg/version"
+	"semtag/pkg/versionControl"
+)
+
+// recordAction records an action performed during the run, for the JSON release plan
+func recordAction(actionType, target string, pushed bool) {
+	actions = append(actions, release.Action{Type: actionType, Target: target, Pushed: pushed})
+}
+
+// printPlan prints the JSON release plan of the repository to stdout; the release is described before tagging (see newRepositoryRelease)
+func printPlan(args internal.CliArgs, r release.Release, notes string) {
+	plan := newPlan(args)
+	plan.Release = r
+	if args.ReleaseNotes && args.ReleaseNotesFile == "" {
+		plan.ReleaseNotes = notes
+	}
+	writePlan(plan)
+}
+
+// printComponentPlan prints the JSON release plan of the released components (or Go modules) to stdout; the releases are described when they are planned
+func printComponentPlan(args internal.CliArgs, releases []componentRelease) {
+	plan := newPlan(args)
+	for _, cr := range releases {
+		plan.Components = append(plan.Components, cr.Summary)
+		plan.ReleaseNeeded = plan.ReleaseNeeded || cr.Summary.ReleaseNeeded
+	}
+	writePlan(plan)
+}
+
+// newRepositoryRelease describes the release of the repository; it must be called before the version is tagged, since the tag becomes the latest tag
+func newRepositoryRelease(args internal.CliArgs, previous, next version.Version) release.Release {
+	r := newRelease(previous, next, nil)
+	hasRelevantChanges, err := versionControl.HasRelevantChanges(args.RelevantPaths)
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+	r.ReleaseNeeded = r.ReleaseNeeded && hasRelevantChanges
+	return r
+}
+
+// newPlan returns the plan with the actions performed during the run
+func newPlan(args internal.CliArgs) release.Plan {
+	plan := release.NewPlan()
+	plan.DryRun = args.DryRun
+	plan.Actions = actions
+	for _, a := range actions {
+		if a.Type == release.ActionFile {
+			plan.FilesChanged = append(plan.FilesChanged, a.Target)
+		}
+	}
+	return plan
+}
+
+// newRelease describes the release of a version; the commits since the previous tag that changed the paths are analyzed, so it must be called before the version is tagged
+func newRelease(previous, next version.Version, paths []string) release.Release {
+	r := release.Release{
+		PreviousVersion: previous.RemovePrefixAndSuffix(previous.String()),
+		NextVersion:     next.RemovePrefixAndSuffix(next.String()),
+		Scope:           next.Scope.String(),
+		Versions:        next.AsList(),
+		Tag:             next.String(),
+		ReleaseNeeded:   next.String() != previous.String(),
+	}
+	// the scope isn't set if the version isn't incremented
+	if !r.ReleaseNeeded {
+		r.Scope = version.Scope{Id: version.NONE}.String()
+	}
+	if tag, err := version.LatestTag(previous.Prefix, previous.Suffix); err == nil {
+		r.PreviousTag = tag
+	}
+	commits, err := GitRepo.GetCommits(r.PreviousTag, "HEAD", paths)
+	if err != nil {
+		output.Logger().Fatal(err)
+	}
+	for _, c := range commits {
+		r.CommitsAnalyzed = append(r.CommitsAnalyzed, release.Commit{Hash: c.Hash, ShortHash: c.ShortHash, Subject: c.Subject})
+	}
+	return r
+}
+
+func writePlan(plan release.Plan) {
+	if err := plan.Write(os.Stdout); err != nil {
+		output.Logger().Fatal(err)
+	}
+}